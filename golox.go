@@ -2,8 +2,11 @@ package main
 
 import (
 	"bufio"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/alxbckr/goloxv1/lox"
 )
@@ -25,6 +28,7 @@ func run(source string) error {
 	}
 
 	resolver := lox.NewResolver(interpreter)
+	resolver.IgnoreWarnings(scan.IgnoredWarnings())
 	err = resolver.ResolveStatements(statements)
 	if err != nil {
 		return err
@@ -46,7 +50,11 @@ func runPrompt() {
 		if line == "" {
 			return
 		}
-		run(line)
+		err := run(line)
+		var exit *lox.ExitError
+		if errors.As(err, &exit) {
+			os.Exit(exit.Code)
+		}
 	}
 }
 
@@ -55,7 +63,12 @@ func runFile(path string) {
 	if err != nil {
 		panic(err)
 	}
+	interpreter.SetScriptPath(path)
 	err = run(string(bytes))
+	var exit *lox.ExitError
+	if errors.As(err, &exit) {
+		os.Exit(exit.Code)
+	}
 	// Indicate an error in the exit code.
 	if err != nil {
 		os.Exit(65)
@@ -63,13 +76,34 @@ func runFile(path string) {
 }
 
 func main() {
+	allowEnv := flag.Bool("allow-env", false, "allow access to environment variables and the working directory")
+	allowExit := flag.Bool("allow-exit", false, "allow scripts to exit the program")
+	allowExec := flag.Bool("allow-exec", false, "allow scripts to run commands")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: golox [flags] [script [args...]]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
 	interpreter = lox.NewInterpreter()
-	args := os.Args
-	if len(args) > 2 {
-		fmt.Printf("Usage: golox [script]")
-		os.Exit(64)
-	} else if len(args) == 2 {
-		runFile(args[1])
+	if loxPath := os.Getenv("LOXPATH"); loxPath != "" {
+		interpreter.SetSearchPath(filepath.SplitList(loxPath))
+	}
+	if *allowEnv {
+		interpreter.Allow(lox.CAP_ENV)
+	}
+	if *allowExit {
+		interpreter.Allow(lox.CAP_EXIT)
+	}
+	if *allowExec {
+		interpreter.Allow(lox.CAP_EXEC)
+	}
+
+	args := flag.Args()
+	if len(args) >= 1 {
+		// Arguments after the script name are passed to the script.
+		interpreter.SetArgs(args[1:])
+		runFile(args[0])
 	} else {
 		runPrompt()
 	}
@@ -1,28 +1,102 @@
 package lox
 
+import "fmt"
+
 type Callable interface {
 	Call(interpreter *Interpreter, arguments []interface{}) interface{}
-	Arity() int
+	// MinArity and MaxArity bound the number of arguments the callable
+	// accepts. MaxArity is VariadicArity if there is no upper bound.
+	MinArity() int
+	MaxArity() int
+}
+
+// NamedCallable is implemented by callables whose parameters can be passed
+// by name.
+type NamedCallable interface {
+	Callable
+	// ParameterNames returns the names of the parameters in order, or nil
+	// if the callable takes no named arguments.
+	ParameterNames() []string
 }
 
+// missingArgument takes the place of optional parameters that are skipped
+// by named arguments.
+var missingArgument = &struct{}{}
+
+// VariadicArity is the maximum arity of callables that accept any number of
+// arguments.
+const VariadicArity = -1
+
 type CallFunc func(interpreter *Interpreter, arguments []interface{}) interface{}
 
 type ProtoCallable struct {
-	arity int
-	call  CallFunc
+	name     string
+	minArity int
+	maxArity int
+	params   []string
+	call     CallFunc
 }
 
 func NewProtoCallable(arity int, call CallFunc) *ProtoCallable {
+	return NewVariadicProtoCallable(arity, arity, call)
+}
+
+func NewVariadicProtoCallable(minArity int, maxArity int, call CallFunc) *ProtoCallable {
 	return &ProtoCallable{
-		arity: arity,
-		call:  call,
+		minArity: minArity,
+		maxArity: maxArity,
+		call:     call,
 	}
 }
 
-func (p *ProtoCallable) Arity() int {
-	return p.arity
+// WithParams declares the parameter names of the native so that it can be
+// called with named arguments.
+func (p *ProtoCallable) WithParams(names ...string) *ProtoCallable {
+	p.params = names
+	return p
+}
+
+func (p *ProtoCallable) String() string {
+	if p.name == "" {
+		return "<native fn>"
+	}
+	return fmt.Sprintf("<native fn %v>", p.name)
+}
+
+// nameNative names value after the name it is bound to if it is a native
+// without a name, and returns it.
+func nameNative(value interface{}, name string) interface{} {
+	if native, ok := value.(*ProtoCallable); ok && native.name == "" {
+		native.name = name
+	}
+	return value
+}
+
+// nameNatives names the natives among values after their keys.
+func nameNatives(values map[string]interface{}) {
+	for name, value := range values {
+		nameNative(value, name)
+	}
+}
+
+func (p *ProtoCallable) ParameterNames() []string {
+	return p.params
+}
+
+func (p *ProtoCallable) MinArity() int {
+	return p.minArity
+}
+
+func (p *ProtoCallable) MaxArity() int {
+	return p.maxArity
 }
 
 func (p *ProtoCallable) Call(interpreter *Interpreter, arguments []interface{}) interface{} {
+	// Natives see skipped optional arguments as nil.
+	for i, argument := range arguments {
+		if argument == missingArgument {
+			arguments[i] = nil
+		}
+	}
 	return p.call(interpreter, arguments)
 }
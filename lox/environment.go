@@ -5,12 +5,14 @@ import "fmt"
 type Environment struct {
 	enclosing *Environment
 	Values    map[string]interface{}
+	constants map[string]bool
 }
 
 func NewEnvironment() *Environment {
 	return &Environment{
 		enclosing: nil,
 		Values:    map[string]interface{}{},
+		constants: map[string]bool{},
 	}
 }
 
@@ -18,15 +20,32 @@ func NewEnvironmentWithEnclosing(enclosing *Environment) *Environment {
 	return &Environment{
 		enclosing: enclosing,
 		Values:    map[string]interface{}{},
+		constants: map[string]bool{},
 	}
 }
 
+// Define binds name to value, replacing a previous variable of the same name.
 func (e *Environment) Define(name string, value interface{}) {
 	e.Values[name] = value
 }
 
+// Declare binds name to value for a declaration in the program. Unlike
+// Define, it refuses to replace a constant.
+func (e *Environment) Declare(name Token, value interface{}) {
+	e.checkDeclarable(name)
+	e.Values[name.Lexeme] = value
+}
+
+// DeclareConstant declares name like Declare, bound to a value that Assign
+// refuses to change.
+func (e *Environment) DeclareConstant(name Token, value interface{}) {
+	e.Declare(name, value)
+	e.constants[name.Lexeme] = true
+}
+
 func (e *Environment) Assign(name Token, value interface{}) {
 	if _, ok := e.Values[name.Lexeme]; ok {
+		e.checkAssignable(name)
 		e.Values[name.Lexeme] = value
 		return
 	}
@@ -52,7 +71,30 @@ func (e *Environment) GetAt(distance int, name string) interface{} {
 }
 
 func (e *Environment) AssignAt(distance int, name Token, value interface{}) {
-	e.ancestor(distance).Values[name.Lexeme] = value
+	env := e.ancestor(distance)
+	env.checkAssignable(name)
+	env.Values[name.Lexeme] = value
+}
+
+func (e *Environment) checkDeclarable(name Token) {
+	if e.constants[name.Lexeme] {
+		panic(NewRuntimeError(name, fmt.Sprintf("can't redeclare constant '%v'.", name.Lexeme)))
+	}
+}
+
+func (e *Environment) checkAssignable(name Token) {
+	if e.constants[name.Lexeme] {
+		panic(NewRuntimeError(name, fmt.Sprintf("can't assign to constant '%v'.", name.Lexeme)))
+	}
+}
+
+// global returns the global environment e belongs to, which is the
+// outermost one below the builtins.
+func (e *Environment) global() *Environment {
+	for e.enclosing != nil && e.enclosing.enclosing != nil {
+		e = e.enclosing
+	}
+	return e
 }
 
 func (e *Environment) ancestor(distance int) *Environment {
@@ -7,12 +7,16 @@ type ExpressionVisitor interface {
 	VisitLiteralExpr(expr Literal) interface{}
 	VisitLogicalExpr(expr Logical) interface{}
 	VisitUnaryExpr(expr Unary) interface{}
-	VisitVariableExpr(expr Variable) interface{}
-	VisitAssignExpr(expr Assign) interface{}
+	VisitVariableExpr(expr *Variable) interface{}
+	VisitAssignExpr(expr *Assign) interface{}
 	VisitGetExpr(expr Get) interface{}
 	VisitSetExpr(expr Set) interface{}
 	VisitSuperExpr(expr *Super) interface{}
-	VisitThisExpr(expr This) interface{}
+	VisitThisExpr(expr *This) interface{}
+	VisitCompoundAssignExpr(expr *CompoundAssign) interface{}
+	VisitCompoundSetExpr(expr CompoundSet) interface{}
+	VisitConditionalExpr(expr Conditional) interface{}
+	VisitOptionalChainExpr(expr OptionalChain) interface{}
 }
 
 type Expr interface {
@@ -25,10 +29,13 @@ type Binary struct {
 	Right    Expr
 }
 
+// Call passes Arguments to Callee. The last len(Names) arguments are named,
+// the ones before them positional.
 type Call struct {
 	Callee    Expr
 	Paren     Token
 	Arguments []Expr
+	Names     []Token
 }
 
 type Grouping struct {
@@ -59,9 +66,19 @@ type Assign struct {
 	Value Expr
 }
 
+// CompoundAssign is an assignment like a += b or a++ that updates a variable
+// with the result of a binary operation on its current value.
+type CompoundAssign struct {
+	Name     Token
+	Operator Token
+	Value    Expr
+	Postfix  bool
+}
+
 type Get struct {
-	Name   Token
-	Object Expr
+	Name     Token
+	Object   Expr
+	Optional bool
 }
 
 type Set struct {
@@ -70,6 +87,29 @@ type Set struct {
 	Value  Expr
 }
 
+// CompoundSet is the CompoundAssign counterpart for fields. The object is
+// evaluated only once.
+type CompoundSet struct {
+	Object   Expr
+	Name     Token
+	Operator Token
+	Value    Expr
+	Postfix  bool
+}
+
+// OptionalChain wraps a chain of property accesses and calls that contains
+// '?.'. Once a '?.' finds nil, the rest of the chain is skipped and the whole
+// chain evaluates to nil.
+type OptionalChain struct {
+	Expression Expr
+}
+
+type Conditional struct {
+	Condition  Expr
+	ThenBranch Expr
+	ElseBranch Expr
+}
+
 type Super struct {
 	Keyword Token
 	Method  Token
@@ -91,11 +131,12 @@ func (b *Binary) Accept(visitor ExpressionVisitor) interface{} {
 	return visitor.VisitBinaryExpr(*b)
 }
 
-func NewCall(callee Expr, paren Token, arguments []Expr) *Call {
+func NewCall(callee Expr, paren Token, arguments []Expr, names []Token) *Call {
 	return &Call{
 		Callee:    callee,
 		Paren:     paren,
 		Arguments: arguments,
+		Names:     names,
 	}
 }
 
@@ -153,7 +194,7 @@ func NewVariable(name Token) *Variable {
 }
 
 func (v *Variable) Accept(visitor ExpressionVisitor) interface{} {
-	return visitor.VisitVariableExpr(*v)
+	return visitor.VisitVariableExpr(v)
 }
 
 func NewAssign(name Token, value Expr) *Assign {
@@ -164,13 +205,27 @@ func NewAssign(name Token, value Expr) *Assign {
 }
 
 func (a *Assign) Accept(visitor ExpressionVisitor) interface{} {
-	return visitor.VisitAssignExpr(*a)
+	return visitor.VisitAssignExpr(a)
+}
+
+func NewCompoundAssign(name Token, operator Token, value Expr, postfix bool) *CompoundAssign {
+	return &CompoundAssign{
+		Name:     name,
+		Operator: operator,
+		Value:    value,
+		Postfix:  postfix,
+	}
+}
+
+func (c *CompoundAssign) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.VisitCompoundAssignExpr(c)
 }
 
-func NewGet(name Token, object Expr) *Get {
+func NewGet(name Token, object Expr, optional bool) *Get {
 	return &Get{
-		Name:   name,
-		Object: object,
+		Name:     name,
+		Object:   object,
+		Optional: optional,
 	}
 }
 
@@ -190,6 +245,42 @@ func (s *Set) Accept(visitor ExpressionVisitor) interface{} {
 	return visitor.VisitSetExpr(*s)
 }
 
+func NewCompoundSet(object Expr, name Token, operator Token, value Expr, postfix bool) *CompoundSet {
+	return &CompoundSet{
+		Object:   object,
+		Name:     name,
+		Operator: operator,
+		Value:    value,
+		Postfix:  postfix,
+	}
+}
+
+func (c *CompoundSet) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.VisitCompoundSetExpr(*c)
+}
+
+func NewConditional(condition Expr, thenBranch Expr, elseBranch Expr) *Conditional {
+	return &Conditional{
+		Condition:  condition,
+		ThenBranch: thenBranch,
+		ElseBranch: elseBranch,
+	}
+}
+
+func (c *Conditional) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.VisitConditionalExpr(*c)
+}
+
+func NewOptionalChain(expression Expr) *OptionalChain {
+	return &OptionalChain{
+		Expression: expression,
+	}
+}
+
+func (o *OptionalChain) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.VisitOptionalChainExpr(*o)
+}
+
 func NewSuper(keyword Token, method Token) *Super {
 	return &Super{
 		Keyword: keyword,
@@ -208,5 +299,5 @@ func NewThis(keyword Token) *This {
 }
 
 func (t *This) Accept(visitor ExpressionVisitor) interface{} {
-	return visitor.VisitThisExpr(*t)
+	return visitor.VisitThisExpr(t)
 }
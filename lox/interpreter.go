@@ -1,40 +1,116 @@
 package lox
 
 import (
+	"bufio"
 	"fmt"
-	"reflect"
-	"time"
+	"io"
+	"math"
+	"os"
+	"strings"
 )
 
 type Interpreter struct {
 	hadRuntimeError bool
+	builtins        *Environment
 	globals         *Environment
 	environment     *Environment
 	locals          map[Expr]int
+	callStack       []callFrame
+	stdin           *bufio.Reader
+	stdout          io.Writer
+	capabilities    Capability
+	clock           Clock
+
+	// Module loading state, see module.go.
+	scriptPath string
+	searchPath []string
+	modules    map[string]*LoxModule
+	loading    []string
+}
+
+// callFrame records a call in progress for exception stack traces and for
+// errors raised by natives.
+type callFrame struct {
+	callee interface{}
+	paren  Token
 }
 
 func NewInterpreter() *Interpreter {
-	env := NewEnvironment()
+	builtins := NewEnvironment()
+
+	builtins.Define("clock", NewProtoCallable(0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		return seconds(interpreter.clock.Now())
+	}))
 
-	env.Define("clock", NewProtoCallable(0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
-		return time.Now().UnixMilli() / 1000.0
+	builtins.Define("Error", NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		return NewLoxException(interpreter.stringify(arguments[0]), 0, "")
 	}))
 
-	return &Interpreter{
+	builtins.Define("list", NewVariadicProtoCallable(0, VariadicArity, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		return NewLoxList(append([]interface{}{}, arguments...))
+	}))
+
+	builtins.Define("map", NewProtoCallable(0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		return NewLoxMap()
+	}))
+
+	defineStringNatives(builtins)
+	defineReflectionNatives(builtins)
+	nameNatives(builtins.Values)
+
+	env := NewEnvironmentWithEnclosing(builtins)
+
+	interpreter := &Interpreter{
 		hadRuntimeError: false,
+		builtins:        builtins,
 		globals:         env,
 		environment:     env,
 		locals:          make(map[Expr]int),
+		stdin:           bufio.NewReader(os.Stdin),
+		stdout:          os.Stdout,
+		capabilities:    DEFAULT_CAPABILITIES,
+		clock:           systemClock{},
+		modules:         make(map[string]*LoxModule),
 	}
+
+	interpreter.defineModule(newMathModule())
+	interpreter.defineModule(newIOModule())
+	interpreter.defineModule(newJSONModule())
+	interpreter.defineModule(newTimeModule())
+	interpreter.defineModule(newReModule())
+	interpreter.defineModule(newOSModule())
+
+	return interpreter
+}
+
+// SetInput sets the reader scripts read from with the io module.
+func (i *Interpreter) SetInput(r io.Reader) {
+	i.stdin = bufio.NewReader(r)
+}
+
+// SetOutput sets the writer print statements and the io module write to.
+func (i *Interpreter) SetOutput(w io.Writer) {
+	i.stdout = w
 }
 
 func (i *Interpreter) Interpret(statements []Stmt) (err error) {
 	defer func() {
 		if val := recover(); val != nil {
-			runtimeError := val.(*RuntimeError)
+			if exit, isExit := val.(*ExitError); isExit {
+				err = exit
+				i.callStack = nil
+				return
+			}
+			runtimeError, ok := val.(*RuntimeError)
+			if wrapper, isThrow := val.(*ThrowWrapper); isThrow {
+				runtimeError = NewRuntimeError(wrapper.Keyword, fmt.Sprintf("uncaught exception: %v", i.safeStringify(wrapper.Value)))
+			} else if !ok {
+				panic(val)
+			}
 			fmt.Println(runtimeError.Error())
 			err = runtimeError
 			i.hadRuntimeError = true
+			i.callStack = nil
 		}
 	}()
 	for _, s := range statements {
@@ -49,12 +125,12 @@ func (i *Interpreter) VisitExpressionStmt(stmt Expression) {
 
 func (i *Interpreter) VisitFunctionStmt(stmt Function) {
 	function := NewLoxFunction(stmt, i.environment, false)
-	i.environment.Define(stmt.Name.Lexeme, function)
+	i.environment.Declare(stmt.Name, function)
 }
 
 func (i *Interpreter) VisitPrintStmt(stmt Print) {
 	value := i.evaluate(stmt.Expression)
-	fmt.Println(stringify(value))
+	fmt.Fprintln(i.stdout, i.stringify(value))
 }
 
 func (i *Interpreter) VisitReturnStmt(stmt Return) {
@@ -65,12 +141,90 @@ func (i *Interpreter) VisitReturnStmt(stmt Return) {
 	panic(NewReturnWrapper(value))
 }
 
+func (i *Interpreter) VisitThrowStmt(stmt Throw) {
+	value := i.evaluate(stmt.Value)
+	if exception, ok := value.(*LoxException); ok && exception.Stack == "" {
+		exception.Line = stmt.Keyword.Line
+		exception.Stack = i.stackTrace(stmt.Keyword.Line)
+	}
+	panic(NewThrowWrapper(stmt.Keyword, value))
+}
+
+func (i *Interpreter) VisitTryStmt(stmt Try) {
+	if stmt.FinallyBody != nil {
+		depth, environment := len(i.callStack), i.environment
+		// Deferred so that it also runs when a return or an uncaught
+		// exception unwinds through the try statement. The calls abandoned
+		// by an exception are popped while it runs, and stay popped if the
+		// finally block ends the unwinding with a return of its own.
+		defer func() {
+			abandoned := append([]callFrame(nil), i.callStack[depth:]...)
+			i.callStack = i.callStack[:depth]
+			i.executeBlock(stmt.FinallyBody, NewEnvironmentWithEnclosing(environment))
+			i.callStack = append(i.callStack, abandoned...)
+		}()
+	}
+
+	if stmt.CatchName == nil {
+		i.executeBlock(stmt.Body, NewEnvironmentWithEnclosing(i.environment))
+		return
+	}
+
+	if thrown, caught := i.tryBlock(stmt.Body); caught {
+		environment := NewEnvironmentWithEnclosing(i.environment)
+		environment.Define(stmt.CatchName.Lexeme, thrown)
+		i.executeBlock(stmt.CatchBody, environment)
+	}
+}
+
+// tryBlock executes body and returns the value it threw, if any. Runtime
+// errors are converted to LoxException values.
+func (i *Interpreter) tryBlock(body []Stmt) (thrown interface{}, caught bool) {
+	depth := len(i.callStack)
+
+	defer func() {
+		val := recover()
+		if val == nil {
+			return
+		}
+		switch v := val.(type) {
+		case *ThrowWrapper:
+			thrown = v.Value
+		case *RuntimeError:
+			thrown = NewLoxException(v.Message, v.Token.Line, i.stackTrace(v.Token.Line))
+		default:
+			panic(val)
+		}
+		i.callStack = i.callStack[:depth]
+		caught = true
+	}()
+
+	i.executeBlock(body, NewEnvironmentWithEnclosing(i.environment))
+	return nil, false
+}
+
+func (i *Interpreter) VisitImportStmt(stmt Import) {
+	module := i.importModule(stmt.Keyword, stmt.Path.Literal.(string))
+
+	if stmt.Alias != nil {
+		i.environment.Declare(*stmt.Alias, module)
+		return
+	}
+	for _, name := range stmt.Names {
+		i.environment.Declare(name, module.Get(name))
+	}
+}
+
 func (i *Interpreter) VisitVarStmt(stmt Var) {
 	var value interface{} = nil
 	if stmt.Initializer != nil {
 		value = i.evaluate(stmt.Initializer)
 	}
-	i.environment.Define(stmt.Name.Lexeme, value)
+	if stmt.Constant {
+		i.environment.DeclareConstant(stmt.Name, value)
+		return
+	}
+	i.environment.Declare(stmt.Name, value)
 }
 
 func (i *Interpreter) VisitWhileStmt(stmt While) {
@@ -93,20 +247,27 @@ func (i *Interpreter) VisitClassStmt(stmt Class) {
 		}
 	}
 
-	i.environment.Define(stmt.Name.Lexeme, nil)
+	i.environment.Declare(stmt.Name, nil)
 
 	if stmt.Superclass != nil {
 		i.environment = NewEnvironmentWithEnclosing(i.environment)
 		i.environment.Define("super", superclass)
 	}
 
-	methods := make(map[string]LoxFunction)
+	methods := make(map[string]*LoxFunction)
 	for _, method := range stmt.Methods {
 		function := NewLoxFunction(method, i.environment, (method.Name.Lexeme == "init"))
-		methods[method.Name.Lexeme] = *function
+		methods[method.Name.Lexeme] = function
 	}
 
-	class := NewLoxClass(stmt.Name.Lexeme, superclass, methods)
+	i.includeTraits(stmt.Traits, superclass, methods)
+
+	class := NewLoxClass(stmt.Name.Lexeme, superclass, methods, i.methodMap(stmt.ClassMethods))
+	class.Getters = i.methodMap(stmt.Getters)
+	class.Setters = i.methodMap(stmt.Setters)
+	for _, name := range stmt.Finals {
+		class.Finals[name.Lexeme] = true
+	}
 
 	if superclass != nil {
 		i.environment = i.environment.enclosing
@@ -115,14 +276,62 @@ func (i *Interpreter) VisitClassStmt(stmt Class) {
 	i.environment.Assign(stmt.Name, class)
 }
 
-func (i *Interpreter) VisitAssignExpr(expr Assign) interface{} {
+func (i *Interpreter) VisitTraitStmt(stmt Trait) {
+	i.environment.Declare(stmt.Name, NewLoxTrait(stmt.Name.Lexeme, i.methodMap(stmt.Methods)))
+}
+
+// includeTraits copies the methods of traits into methods, unless the class
+// declares them itself. Each copy closes over an environment binding 'super'
+// to the including class's superclass. A method provided by two traits is an
+// error.
+func (i *Interpreter) includeTraits(traits []*Variable, superclass *LoxClass, methods map[string]*LoxFunction) {
+	providedBy := make(map[string]string)
+	for _, expr := range traits {
+		trait, ok := i.evaluate(expr).(*LoxTrait)
+		if !ok {
+			panic(NewRuntimeError(expr.Name, fmt.Sprintf("'%v' is not a trait.", expr.Name.Lexeme)))
+		}
+
+		for name, method := range trait.Methods {
+			if other, ok := providedBy[name]; ok {
+				panic(NewRuntimeError(expr.Name, fmt.Sprintf("method '%v' is provided by both traits %v and %v.", name, other, trait.Name)))
+			}
+			if _, ok := methods[name]; ok {
+				continue
+			}
+			providedBy[name] = trait.Name
+
+			environment := NewEnvironmentWithEnclosing(method.Closure)
+			environment.Define("super", superclass)
+			methods[name] = NewLoxFunction(method.Declaration, environment, name == "init")
+		}
+	}
+}
+
+// methodMap creates the functions declared in a class body, closing over the
+// current environment, keyed by name.
+func (i *Interpreter) methodMap(declarations []Function) map[string]*LoxFunction {
+	methods := make(map[string]*LoxFunction)
+	for _, method := range declarations {
+		function := NewLoxFunction(method, i.environment, false)
+		methods[method.Name.Lexeme] = function
+	}
+	return methods
+}
+
+func (i *Interpreter) VisitAssignExpr(expr *Assign) interface{} {
 	value := i.evaluate(expr.Value)
+	i.assignVariable(expr.Name, expr, value)
+	return value
+}
 
-	distance, ok := i.locals[&expr]
-	if ok {
-		i.environment.AssignAt(distance, expr.Name, value)
-	} else {
-		i.globals.Assign(expr.Name, value)
+func (i *Interpreter) VisitCompoundAssignExpr(expr *CompoundAssign) interface{} {
+	current := i.lookUpVariable(expr.Name, expr)
+	value := i.compoundUpdate(expr.Operator, expr.Name, current, i.evaluate(expr.Value))
+	i.assignVariable(expr.Name, expr, value)
+
+	if expr.Postfix {
+		return current
 	}
 	return value
 }
@@ -142,33 +351,100 @@ func (i *Interpreter) VisitLiteralExpr(expr Literal) interface{} {
 func (i *Interpreter) VisitLogicalExpr(expr Logical) interface{} {
 	left := i.evaluate(expr.Left)
 
-	if expr.Operator.TokenType == OR {
+	switch expr.Operator.TokenType {
+	case OR:
 		if isTruthy(left) {
 			return left
 		}
-	} else if !isTruthy(left) {
-		return left
+	case QUESTION_QUESTION:
+		if left != nil {
+			return left
+		}
+	default:
+		if !isTruthy(left) {
+			return left
+		}
 	}
 	return i.evaluate(expr.Right)
 }
 
+func (i *Interpreter) VisitConditionalExpr(expr Conditional) interface{} {
+	if isTruthy(i.evaluate(expr.Condition)) {
+		return i.evaluate(expr.ThenBranch)
+	}
+	return i.evaluate(expr.ElseBranch)
+}
+
 func (i *Interpreter) VisitSetExpr(expr Set) interface{} {
 	object := i.evaluate(expr.Object)
 
-	obj, ok := (object).(*LoxInstance)
+	obj, ok := (object).(Setter)
 	if !ok {
-		panic(NewRuntimeError(expr.Name, "only instances have fields."))
+		panic(NewRuntimeError(expr.Name, "only instances and classes have fields."))
 	}
 
 	value := i.evaluate(expr.Value)
-	obj.Set(expr.Name, value)
+	i.setProperty(obj, expr.Name, value)
 	return value
 }
 
+func (i *Interpreter) VisitCompoundSetExpr(expr CompoundSet) interface{} {
+	object := i.evaluate(expr.Object)
+
+	obj, ok := (object).(Setter)
+	if !ok {
+		panic(NewRuntimeError(expr.Name, "only instances and classes have fields."))
+	}
+
+	current := i.getProperty(obj, expr.Name)
+	value := i.compoundUpdate(expr.Operator, expr.Name, current, i.evaluate(expr.Value))
+	i.setProperty(obj, expr.Name, value)
+
+	if expr.Postfix {
+		return current
+	}
+	return value
+}
+
+// compoundUpdate applies the operator of a compound assignment or of '++'
+// and '--' to the current value of name.
+func (i *Interpreter) compoundUpdate(operator Token, name Token, current interface{}, value interface{}) interface{} {
+	if current == nil {
+		panic(NewRuntimeError(operator, fmt.Sprintf("can't apply '%v' to '%v' because it is nil.", operator.Lexeme, name.Lexeme)))
+	}
+	return i.binary(operator, current, value)
+}
+
+// getProperty and setProperty read and assign a property of object, running
+// the getters and setters of instances.
+func (i *Interpreter) getProperty(object Setter, name Token) interface{} {
+	if instance, ok := object.(*LoxInstance); ok {
+		return instance.GetProperty(i, name)
+	}
+	return object.Get(name)
+}
+
+func (i *Interpreter) setProperty(object Setter, name Token, value interface{}) {
+	if instance, ok := object.(*LoxInstance); ok {
+		instance.SetProperty(i, name, value)
+		return
+	}
+	object.Set(name, value)
+}
+
 func (i *Interpreter) VisitSuperExpr(expr *Super) interface{} {
 	distance := i.locals[expr]
-	superclass := (i.environment.GetAt(distance, "super")).(*LoxClass)
-	object := (i.environment.GetAt(distance-1, "this")).(*LoxInstance)
+	superclass, _ := (i.environment.GetAt(distance, "super")).(*LoxClass)
+	if superclass == nil {
+		panic(NewRuntimeError(expr.Keyword, "can't use 'super' in a class with no superclass"))
+	}
+	object := i.environment.GetAt(distance-1, "this")
+
+	// Inside a class method 'this' is the class, so look up the superclass's
+	// class methods instead of its instance methods.
+	if _, ok := object.(*LoxClass); ok {
+		superclass = superclass.Metaclass
+	}
 	method := superclass.FindMethod(expr.Method.Lexeme)
 
 	if method == nil {
@@ -185,12 +461,19 @@ func (i *Interpreter) VisitGroupingExpr(expr Grouping) interface{} {
 func (i *Interpreter) VisitUnaryExpr(expr Unary) interface{} {
 	right := i.evaluate(expr.Right)
 
+	if value, ok := i.unary(expr.Operator, right); ok {
+		return value
+	}
+
 	switch expr.Operator.TokenType {
 	case BANG:
 		return !isTruthy(right)
 	case MINUS:
 		checkNumberOperand(expr.Operator, right)
 		return -right.(float64)
+	case TILDE:
+		checkIntegerOperand(expr.Operator, right)
+		return float64(^int64(right.(float64)))
 	}
 	// unreachable
 	return nil
@@ -199,41 +482,72 @@ func (i *Interpreter) VisitUnaryExpr(expr Unary) interface{} {
 func (i *Interpreter) VisitBinaryExpr(expr Binary) interface{} {
 	left := i.evaluate(expr.Left)
 	right := i.evaluate(expr.Right)
+	return i.binary(expr.Operator, left, right)
+}
 
-	switch expr.Operator.TokenType {
+func binaryOperation(operator Token, left interface{}, right interface{}) interface{} {
+	switch operator.TokenType {
 	case GREATER:
-		checkNumberOperands(expr.Operator, left, right)
+		checkNumberOperands(operator, left, right)
 		return left.(float64) > right.(float64)
 	case LESS:
-		checkNumberOperands(expr.Operator, left, right)
+		checkNumberOperands(operator, left, right)
 		return left.(float64) < right.(float64)
 	case GREATER_EQUAL:
-		checkNumberOperands(expr.Operator, left, right)
+		checkNumberOperands(operator, left, right)
 		return left.(float64) >= right.(float64)
 	case LESS_EQUAL:
-		checkNumberOperands(expr.Operator, left, right)
+		checkNumberOperands(operator, left, right)
 		return left.(float64) <= right.(float64)
 	case BANG_EQUAL:
 		return !isEqual(left, right)
 	case EQUAL_EQUAL:
 		return isEqual(left, right)
 	case MINUS:
-		checkNumberOperands(expr.Operator, left, right)
+		checkNumberOperands(operator, left, right)
 		return left.(float64) - right.(float64)
 	case PLUS:
-		if reflect.TypeOf(left).Kind() == reflect.Float64 && reflect.TypeOf(right).Kind() == reflect.Float64 {
-			return left.(float64) + right.(float64)
+		if l, ok := left.(float64); ok {
+			if r, ok := right.(float64); ok {
+				return l + r
+			}
 		}
-		if reflect.TypeOf(left).Kind() == reflect.String && reflect.TypeOf(right).Kind() == reflect.String {
-			return left.(string) + right.(string)
+		if l, ok := left.(string); ok {
+			if r, ok := right.(string); ok {
+				return l + r
+			}
 		}
-		panic(NewRuntimeError(expr.Operator, "operands must be two nubmers or two strings"))
+		panic(NewRuntimeError(operator, "operands must be two nubmers or two strings"))
 	case SLASH:
-		checkNumberOperands(expr.Operator, left, right)
+		checkNumberOperands(operator, left, right)
 		return left.(float64) / right.(float64)
 	case STAR:
-		checkNumberOperands(expr.Operator, left, right)
+		checkNumberOperands(operator, left, right)
 		return left.(float64) * right.(float64)
+	case PERCENT:
+		checkNumberOperands(operator, left, right)
+		return math.Mod(left.(float64), right.(float64))
+	case TILDE_SLASH:
+		checkNumberOperands(operator, left, right)
+		return math.Floor(left.(float64) / right.(float64))
+	case STAR_STAR:
+		checkNumberOperands(operator, left, right)
+		return math.Pow(left.(float64), right.(float64))
+	case AMPERSAND:
+		checkIntegerOperands(operator, left, right)
+		return float64(int64(left.(float64)) & int64(right.(float64)))
+	case PIPE:
+		checkIntegerOperands(operator, left, right)
+		return float64(int64(left.(float64)) | int64(right.(float64)))
+	case CARET:
+		checkIntegerOperands(operator, left, right)
+		return float64(int64(left.(float64)) ^ int64(right.(float64)))
+	case LESS_LESS:
+		checkShiftOperands(operator, left, right)
+		return float64(int64(left.(float64)) << int64(right.(float64)))
+	case GREATER_GREATER:
+		checkShiftOperands(operator, left, right)
+		return float64(int64(left.(float64)) >> int64(right.(float64)))
 	}
 	// unreachable
 	return nil
@@ -247,32 +561,148 @@ func (i *Interpreter) VisitCallExpr(expr Call) interface{} {
 		arguments = append(arguments, i.evaluate(a))
 	}
 
+	if instance, ok := callee.(*LoxInstance); ok {
+		if method := instance.Class.FindMethod("__call__"); method != nil {
+			callee = method.Bind(instance)
+		}
+	}
+
 	f, ok := callee.(Callable)
 	if !ok {
 		panic(NewRuntimeError(expr.Paren, "can only call functions and classes"))
 	}
 
-	if len(arguments) != f.Arity() {
-		panic(NewRuntimeError(expr.Paren, fmt.Sprintf("expected %v arguments but got %v.", f.Arity(), len(arguments))))
+	if len(expr.Names) > 0 {
+		arguments = arrangeArguments(expr.Paren, f, arguments, expr.Names)
+	}
+
+	checkArity(expr.Paren, f, len(arguments))
+
+	// The frame is popped only on a normal return so that an exception
+	// still sees the stack it was raised in.
+	i.callStack = append(i.callStack, callFrame{callee: callee, paren: expr.Paren})
+	value := f.Call(i, arguments)
+	i.callStack = i.callStack[:len(i.callStack)-1]
+	return value
+}
+
+// arrangeArguments moves the trailing named arguments to the positions of the
+// parameters they name.
+func arrangeArguments(paren Token, f Callable, arguments []interface{}, names []Token) []interface{} {
+	var params []string
+	if named, ok := f.(NamedCallable); ok {
+		params = named.ParameterNames()
+	}
+	if params == nil {
+		panic(NewRuntimeError(paren, "callee doesn't accept named arguments."))
+	}
+
+	positional := len(arguments) - len(names)
+	size := len(params)
+	if positional > size {
+		size = positional
+	}
+	arranged := make([]interface{}, size)
+	given := make([]bool, size)
+	copy(arranged, arguments[:positional])
+	for j := 0; j < positional; j++ {
+		given[j] = true
+	}
+
+	count := positional
+	for j, name := range names {
+		index := -1
+		for k, param := range params {
+			if param == name.Lexeme {
+				index = k
+				break
+			}
+		}
+		if index < 0 {
+			panic(NewRuntimeError(name, fmt.Sprintf("unknown parameter '%v'.", name.Lexeme)))
+		}
+		if given[index] {
+			panic(NewRuntimeError(name, fmt.Sprintf("argument for parameter '%v' given more than once.", name.Lexeme)))
+		}
+		arranged[index] = arguments[positional+j]
+		given[index] = true
+		if index >= count {
+			count = index + 1
+		}
+	}
+
+	for j := 0; j < count; j++ {
+		if given[j] {
+			continue
+		}
+		if j < f.MinArity() {
+			panic(NewRuntimeError(paren, fmt.Sprintf("missing argument for parameter '%v'.", params[j])))
+		}
+		arranged[j] = missingArgument
+	}
+	return arranged[:count]
+}
+
+func checkArity(paren Token, f Callable, count int) {
+	min, max := f.MinArity(), f.MaxArity()
+	if count >= min && (count <= max || max == VariadicArity) {
+		return
 	}
 
-	return f.Call(i, arguments)
+	panic(NewRuntimeError(paren, fmt.Sprintf("expected %v arguments but got %v.", arityDescription(min, max), count)))
+}
+
+// arityDescription describes the number of arguments a callable accepts.
+func arityDescription(min int, max int) string {
+	switch {
+	case min == max:
+		return fmt.Sprint(min)
+	case max == VariadicArity:
+		return fmt.Sprintf("at least %v", min)
+	default:
+		return fmt.Sprintf("%v to %v", min, max)
+	}
+}
+
+// shortCircuit unwinds an optional chain to its OptionalChain once a '?.'
+// finds nil.
+type shortCircuit struct{}
+
+func (i *Interpreter) VisitOptionalChainExpr(expr OptionalChain) (value interface{}) {
+	defer func() {
+		if val := recover(); val != nil {
+			if _, ok := val.(shortCircuit); !ok {
+				panic(val)
+			}
+			value = nil
+		}
+	}()
+	return i.evaluate(expr.Expression)
 }
 
 func (i *Interpreter) VisitGetExpr(expr Get) interface{} {
 	object := i.evaluate(expr.Object)
-	if inst, ok := object.(*LoxInstance); ok {
-		return inst.Get(expr.Name)
+	if obj, ok := object.(*LoxInstance); ok {
+		return obj.GetProperty(i, expr.Name)
+	}
+	if obj, ok := object.(Getter); ok {
+		return nameNative(obj.Get(expr.Name), expr.Name.Lexeme)
+	}
+	if s, ok := object.(string); ok {
+		return nameNative(stringMethod(s, expr.Name), expr.Name.Lexeme)
+	}
+	if object == nil && expr.Optional {
+		panic(shortCircuit{})
 	}
 	panic(NewRuntimeError(expr.Name, "only instances have properties"))
 }
 
-func (i *Interpreter) VisitVariableExpr(expr Variable) interface{} {
-	return i.lookUpVariable(expr.Name, &expr)
+func (i *Interpreter) VisitVariableExpr(expr *Variable) interface{} {
+	return i.lookUpVariable(expr.Name, expr)
 }
 
-func (i *Interpreter) VisitThisExpr(expr This) interface{} {
-	return i.lookUpVariable(expr.Keyword, &expr)
+func (i *Interpreter) VisitThisExpr(expr *This) interface{} {
+	return i.lookUpVariable(expr.Keyword, expr)
 }
 
 func (i *Interpreter) lookUpVariable(name Token, expr Expr) interface{} {
@@ -280,10 +710,31 @@ func (i *Interpreter) lookUpVariable(name Token, expr Expr) interface{} {
 	if ok {
 		return i.environment.GetAt(distance, name.Lexeme)
 	} else {
-		return i.environment.Get(name)
+		return i.globals.Get(name)
+	}
+}
+
+func (i *Interpreter) assignVariable(name Token, expr Expr, value interface{}) {
+	distance, ok := i.locals[expr]
+	if ok {
+		i.environment.AssignAt(distance, name, value)
+	} else {
+		i.globals.Assign(name, value)
 	}
 }
 
+// stackTrace describes the calls in progress, innermost first, for an
+// exception raised at line.
+func (i *Interpreter) stackTrace(line int) string {
+	var trace strings.Builder
+	fmt.Fprintf(&trace, "[line %v]", line)
+	for j := len(i.callStack) - 1; j >= 0; j-- {
+		frame := i.callStack[j]
+		fmt.Fprintf(&trace, "\n  in %v called from [line %v]", stringify(frame.callee), frame.paren.Line)
+	}
+	return trace.String()
+}
+
 func (i *Interpreter) evaluate(expr Expr) interface{} {
 	return expr.Accept(i)
 }
@@ -322,14 +773,15 @@ func isTruthy(value interface{}) bool {
 	return true
 }
 
+// isEqual compares numbers, strings and booleans by value and every other
+// object by identity.
 func isEqual(left interface{}, right interface{}) bool {
-	if left == nil && right == nil {
-		return true
-	}
-	if left == nil {
-		return false
+	if l, ok := left.(*LoxFunction); ok {
+		if r, ok := right.(*LoxFunction); ok {
+			return l.Equal(r)
+		}
 	}
-	return reflect.ValueOf(left).Equal(reflect.ValueOf(right))
+	return left == right
 }
 
 func checkNumberOperand(operator Token, operand interface{}) {
@@ -351,6 +803,35 @@ func checkNumberOperands(operator Token, left interface{}, right interface{}) {
 
 }
 
+func isInteger(operand interface{}) bool {
+	v, ok := operand.(float64)
+	return ok && v == math.Trunc(v) && v >= math.MinInt64 && v < math.MaxInt64
+}
+
+func checkIntegerOperand(operator Token, operand interface{}) {
+	if !isInteger(operand) {
+		panic(NewRuntimeError(operator, "operand must be an integer"))
+	}
+}
+
+func checkIntegerOperands(operator Token, left interface{}, right interface{}) {
+	if !isInteger(left) || !isInteger(right) {
+		panic(NewRuntimeError(operator, "operands must be integers"))
+	}
+}
+
+func checkShiftOperands(operator Token, left interface{}, right interface{}) {
+	checkIntegerOperands(operator, left, right)
+	if right.(float64) < 0 {
+		panic(NewRuntimeError(operator, "shift count must not be negative"))
+	}
+}
+
+// stringifyCallable exposes stringify to desugared string interpolations.
+var stringifyCallable = NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+	return interpreter.stringify(arguments[0])
+})
+
 func stringify(object interface{}) string {
 	if object == nil {
 		return "nil"
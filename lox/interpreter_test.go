@@ -0,0 +1,292 @@
+package lox
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+// run scans, parses, resolves and interprets source, and returns what the
+// program printed.
+func run(t *testing.T, source string) string {
+	t.Helper()
+	return interpret(t, NewInterpreter(), source)
+}
+
+// interpret runs source like run, with an interpreter set up by the caller.
+func interpret(t *testing.T, interpreter *Interpreter, source string) string {
+	t.Helper()
+
+	output, err := tryInterpret(t, interpreter, source)
+	if err != nil {
+		t.Fatalf("interpret: %v", err)
+	}
+	return output
+}
+
+// tryInterpret is like interpret, but returns the runtime error that stopped
+// the program.
+func tryInterpret(t *testing.T, interpreter *Interpreter, source string) (string, error) {
+	t.Helper()
+
+	tokens, err := NewScanner(source).ScanTokens()
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	statements, err := NewParser(tokens).Parse()
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	var out bytes.Buffer
+	interpreter.SetOutput(&out)
+
+	resolver := NewResolver(interpreter)
+	resolver.SetWarningOutput(io.Discard)
+	if err := resolver.ResolveStatements(statements); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	err = interpreter.Interpret(statements)
+	return out.String(), err
+}
+
+func expectOutput(t *testing.T, source string, expected ...string) {
+	t.Helper()
+
+	want := strings.Join(expected, "\n") + "\n"
+	if output := run(t, source); output != want {
+		t.Errorf("got output\n%vwant\n%v", output, want)
+	}
+}
+
+func expectRuntimeError(t *testing.T, source string, message string) {
+	t.Helper()
+
+	_, err := tryInterpret(t, NewInterpreter(), source)
+	if err == nil || err.Error() != message {
+		t.Errorf("got error %v, want %v", err, message)
+	}
+}
+
+func TestClassEquality(t *testing.T) {
+	expectOutput(t, `
+class A {
+  m() {}
+}
+var a = A();
+var b = A();
+print a == a;
+print a == b;
+print a.m == a.m;
+print a.m == b.m;
+print A == A;
+print classOf(a) == A;
+`, "true", "false", "true", "false", "true", "true")
+}
+
+func TestAddNil(t *testing.T) {
+	expectOutput(t, `
+try { nil + 1; } catch (e) { print e.message; }
+try { "a" + nil; } catch (e) { print e.message; }
+print 1 + 2;
+print "a" + "b";
+`, "operands must be two nubmers or two strings", "operands must be two nubmers or two strings", "3.000000", "ab")
+}
+
+func TestCompoundUpdateNil(t *testing.T) {
+	expectOutput(t, `
+var count;
+try { count++; } catch (e) { print e.message; }
+try { count += 1; } catch (e) { print e.message; }
+class C {}
+var c = C();
+c.n = nil;
+try { --c.n; } catch (e) { print e.message; }
+count = 1;
+count++;
+print count;
+`, "can't apply '++' to 'count' because it is nil.", "can't apply '+=' to 'count' because it is nil.", "can't apply '--' to 'n' because it is nil.", "2.000000")
+}
+
+func TestOptionalChain(t *testing.T) {
+	expectOutput(t, `
+var m;
+print m?.a.b;
+print m?.f();
+class P {
+  init() { this.a = nil; }
+  f() { return this; }
+}
+var p = P();
+print p?.f().a;
+try { p?.a.b; } catch (e) { print e.message; }
+`, "nil", "nil", "nil", "only instances have properties")
+}
+
+func TestNativeStackTrace(t *testing.T) {
+	expectOutput(t, `
+import "math" as math;
+print math.sqrt;
+fun f() { return math.sqrt("x"); }
+try { f(); } catch (e) { print e.stack; }
+`, "<native fn sqrt>", "[line 4]", "  in <native fn sqrt> called from [line 4]", "  in <fn f> called from [line 5]")
+}
+
+func TestJSONSerializeError(t *testing.T) {
+	expectOutput(t, `
+import "json" as json;
+try { json.stringify(clock); } catch (e) { print e.message; }
+`, "json.stringify: can't serialize function.")
+}
+
+func TestReturnFromFinally(t *testing.T) {
+	expectOutput(t, `
+fun inner() { throw Error("inner"); }
+fun f() {
+  try {
+    inner();
+  } finally {
+    return 1;
+  }
+}
+print f();
+print f();
+try { inner(); } catch (e) { print e.stack; }
+fun g() {
+  try {
+    inner();
+  } finally {
+    print "finally";
+  }
+}
+try { g(); } catch (e) { print e.stack; }
+`, "1.000000", "1.000000", "[line 2]", "  in <fn inner> called from [line 12]",
+		"finally", "[line 2]", "  in <fn inner> called from [line 15]", "  in <fn g> called from [line 20]")
+}
+
+func TestPrintCyclicContainers(t *testing.T) {
+	expectOutput(t, `
+var l = list(1);
+l.push(l);
+print l;
+var m = map();
+m.set("self", m);
+m.set("list", l);
+print m;
+print str(list(l, l));
+`, "[1.000000, [...]]", "{self: {...}, list: [1.000000, [...]]}", "[[1.000000, [...]], [1.000000, [...]]]")
+}
+
+func TestRepeatTooLong(t *testing.T) {
+	expectOutput(t, `
+try { "ab".repeat(9e18); } catch (e) { print e.message; }
+print "ab".repeat(2);
+print "".repeat(9e18) == "";
+`, "repeat: result would be longer than 1073741824 bytes.", "abab", "true")
+}
+
+func TestRedeclareConstant(t *testing.T) {
+	expectRuntimeError(t, `
+const X = 1;
+var X = 5;
+`, "can't redeclare constant 'X'. [line 3]")
+	expectRuntimeError(t, `
+const X = 1;
+fun X() {}
+`, "can't redeclare constant 'X'. [line 3]")
+}
+
+func TestUncaughtExceptionMessage(t *testing.T) {
+	expectRuntimeError(t, `
+class Problem {
+  __str__() { return "a problem"; }
+}
+throw Problem();
+`, "uncaught exception: a problem [line 5]")
+	expectRuntimeError(t, `
+class Broken {
+  __str__() { return nil; }
+}
+throw Broken();
+`, "uncaught exception: Broken instance [line 5]")
+}
+
+func TestTryCatchFinally(t *testing.T) {
+	expectOutput(t, `
+fun f() {
+  try {
+    return "try";
+  } finally {
+    print "finally";
+  }
+}
+print f();
+
+try {
+  nil.x;
+} catch (e) {
+  print e.message;
+  print e.line;
+} finally {
+  print "done";
+}
+
+class Oops {
+  init(code) { this.code = code; }
+}
+try { throw Oops(7); } catch (e) { print e.code; }
+
+fun g() {
+  try {
+    throw Error("inner");
+  } catch (e) {
+    return e.message;
+  } finally {
+    print "cleanup";
+  }
+}
+print g();
+`, "finally", "try", "only instances have properties", "12.000000", "done", "7.000000", "cleanup", "inner")
+}
+
+func TestDefaultAndRestParameters(t *testing.T) {
+	expectOutput(t, `
+var calls = 0;
+fun next() { calls = calls + 1; return calls; }
+fun f(a, b = a * 2, c = next(), ...rest) {
+  print "${a} ${b} ${c} ${rest}";
+}
+f(1);
+f(1, 5);
+f(1, 5, 7, 8, 9);
+try { f(); } catch (e) { print e.message; }
+fun g(a, b = 1) {}
+try { g(1, 2, 3); } catch (e) { print e.message; }
+print math.max(3, 1, 2);
+print arity(g);
+`, "1.000000 2.000000 1.000000 []", "1.000000 5.000000 2.000000 []",
+		"1.000000 5.000000 7.000000 [8.000000, 9.000000]",
+		"expected at least 1 arguments but got 0.", "expected 1 to 2 arguments but got 3.",
+		"3.000000", "1.000000")
+}
+
+func TestNamedArguments(t *testing.T) {
+	expectOutput(t, `
+fun connect(host, port = 80, secure = false) {
+  return "${host}:${port} ${secure}";
+}
+print connect(port: 8080, host: "x");
+print connect("x", secure: true);
+try { connect(host: "x", user: "me"); } catch (e) { print e.message; }
+try { connect("x", host: "y"); } catch (e) { print e.message; }
+try { connect(port: 1); } catch (e) { print e.message; }
+try { clock(now: 1); } catch (e) { print e.message; }
+class A {
+  init() { this.x = 1; }
+}
+print getField(name: "x", object: A());
+`, "x:8080.000000 false", "x:80.000000 true", "unknown parameter 'user'.",
+		"argument for parameter 'host' given more than once.", "missing argument for parameter 'host'.",
+		"callee doesn't accept named arguments.", "1.000000")
+}
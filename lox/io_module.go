@@ -0,0 +1,107 @@
+package lox
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+func newIOModule() *LoxModule {
+	values := map[string]interface{}{}
+
+	values["write"] = NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		fmt.Fprint(interpreter.stdout, interpreter.stringify(arguments[0]))
+		return nil
+	})
+
+	// readLine returns the next line without its line ending, or nil at the
+	// end of the input.
+	values["readLine"] = NewProtoCallable(0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		line, err := interpreter.stdin.ReadString('\n')
+		if err != nil && err != io.EOF {
+			interpreter.nativeError("readLine: %v.", err)
+		}
+		if err == io.EOF && line == "" {
+			return nil
+		}
+		return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+	})
+
+	values["readAll"] = NewProtoCallable(0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		data, err := io.ReadAll(interpreter.stdin)
+		if err != nil {
+			interpreter.nativeError("readAll: %v.", err)
+		}
+		return string(data)
+	})
+
+	values["readFile"] = NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		path := interpreter.filesystemArgument("readFile", arguments)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			interpreter.nativeError("readFile: %v.", err)
+		}
+		return string(data)
+	})
+
+	values["writeFile"] = NewProtoCallable(2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		path := interpreter.filesystemArgument("writeFile", arguments)
+		data := interpreter.stringArgument("writeFile", arguments, 1)
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			interpreter.nativeError("writeFile: %v.", err)
+		}
+		return nil
+	})
+
+	values["appendFile"] = NewProtoCallable(2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		path := interpreter.filesystemArgument("appendFile", arguments)
+		data := interpreter.stringArgument("appendFile", arguments, 1)
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err == nil {
+			_, err = file.WriteString(data)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		if err != nil {
+			interpreter.nativeError("appendFile: %v.", err)
+		}
+		return nil
+	})
+
+	values["exists"] = NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		path := interpreter.filesystemArgument("exists", arguments)
+		_, err := os.Stat(path)
+		return err == nil
+	})
+
+	values["listDir"] = NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		path := interpreter.filesystemArgument("listDir", arguments)
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			interpreter.nativeError("listDir: %v.", err)
+		}
+		names := make([]string, len(entries))
+		for j, entry := range entries {
+			names[j] = entry.Name()
+		}
+		sort.Strings(names)
+
+		elements := make([]interface{}, len(names))
+		for j, name := range names {
+			elements[j] = name
+		}
+		return NewLoxList(elements)
+	})
+
+	return NewLoxModule("io", "", values)
+}
+
+// filesystemArgument checks that the native name may access the filesystem
+// and returns the path passed as its first argument.
+func (i *Interpreter) filesystemArgument(name string, arguments []interface{}) string {
+	i.requireCapability(CAP_FILESYSTEM, name, "filesystem access")
+	return i.stringArgument(name, arguments, 0)
+}
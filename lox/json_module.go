@@ -0,0 +1,202 @@
+package lox
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func newJSONModule() *LoxModule {
+	values := map[string]interface{}{}
+
+	values["parse"] = NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		decoder := json.NewDecoder(strings.NewReader(interpreter.stringArgument("parse", arguments, 0)))
+		value, err := decodeJSON(decoder)
+		if err == nil {
+			if _, extra := decoder.Token(); extra != io.EOF {
+				err = fmt.Errorf("unexpected data after top-level value")
+			}
+		}
+		if err != nil {
+			interpreter.nativeError("json.parse: %v.", err)
+		}
+		return value
+	})
+
+	// stringify(value) writes compact JSON, stringify(value, indent) indents
+	// nested values by indent spaces or by the indent string.
+	values["stringify"] = NewVariadicProtoCallable(1, 2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		indent := ""
+		if len(arguments) == 2 {
+			switch v := arguments[1].(type) {
+			case nil:
+			case string:
+				indent = v
+			case float64:
+				indent = strings.Repeat(" ", interpreter.integerArgument("json.stringify", arguments, 1))
+			default:
+				interpreter.nativeError("json.stringify: indent must be a number or a string.")
+			}
+		}
+
+		encoder := &jsonEncoder{indent: indent, visiting: map[interface{}]bool{}}
+		if err := encoder.encode(arguments[0], 0); err != nil {
+			interpreter.nativeError("json.stringify: %v.", err)
+		}
+		return encoder.out.String()
+	}).WithParams("value", "indent")
+
+	return NewLoxModule("json", "", values)
+}
+
+// decodeJSON reads the next value from decoder, keeping the key order of
+// objects.
+func decodeJSON(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err == io.EOF {
+		return nil, fmt.Errorf("unexpected end of JSON input")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := token.(type) {
+	case json.Delim:
+		switch v {
+		case '[':
+			elements := []interface{}{}
+			for decoder.More() {
+				element, err := decodeJSON(decoder)
+				if err != nil {
+					return nil, err
+				}
+				elements = append(elements, element)
+			}
+			// The closing ].
+			if _, err := decoder.Token(); err != nil {
+				return nil, err
+			}
+			return NewLoxList(elements), nil
+		case '{':
+			object := NewLoxMap()
+			for decoder.More() {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				value, err := decodeJSON(decoder)
+				if err != nil {
+					return nil, err
+				}
+				object.Put(key.(string), value)
+			}
+			// The closing }.
+			if _, err := decoder.Token(); err != nil {
+				return nil, err
+			}
+			return object, nil
+		}
+		return nil, fmt.Errorf("unexpected '%v'", v)
+	default:
+		// Strings, float64 numbers, booleans and nil map directly.
+		return v, nil
+	}
+}
+
+type jsonEncoder struct {
+	out      bytes.Buffer
+	indent   string
+	visiting map[interface{}]bool
+}
+
+func (e *jsonEncoder) encode(value interface{}, depth int) error {
+	switch v := value.(type) {
+	case nil:
+		e.out.WriteString("null")
+	case bool:
+		e.out.WriteString(strconv.FormatBool(v))
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return fmt.Errorf("can't serialize %v", v)
+		}
+		e.out.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+	case string:
+		e.encodeString(v)
+	case *LoxList:
+		return e.encodeContainer(v, '[', ']', len(v.Elements), depth, func(i int) error {
+			return e.encode(v.Elements[i], depth+1)
+		})
+	case *LoxMap:
+		return e.encodeContainer(v, '{', '}', len(v.Keys), depth, func(i int) error {
+			return e.encodeField(v.Keys[i], v.Entries[v.Keys[i]], depth)
+		})
+	case *LoxInstance:
+		keys := make([]string, 0, len(v.Fields))
+		for key := range v.Fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return e.encodeContainer(v, '{', '}', len(keys), depth, func(i int) error {
+			return e.encodeField(keys[i], v.Fields[keys[i]], depth)
+		})
+	default:
+		return fmt.Errorf("can't serialize %v", typeName(value))
+	}
+	return nil
+}
+
+// encodeContainer writes the n items of a list or object between open and
+// close, guarding against containers that contain themselves.
+func (e *jsonEncoder) encodeContainer(container interface{}, open byte, close byte, n int, depth int, item func(i int) error) error {
+	if e.visiting[container] {
+		return fmt.Errorf("can't serialize cyclic structure")
+	}
+	e.visiting[container] = true
+	defer delete(e.visiting, container)
+
+	e.out.WriteByte(open)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			e.out.WriteByte(',')
+		}
+		e.newline(depth + 1)
+		if err := item(i); err != nil {
+			return err
+		}
+	}
+	if n > 0 {
+		e.newline(depth)
+	}
+	e.out.WriteByte(close)
+	return nil
+}
+
+func (e *jsonEncoder) encodeField(key string, value interface{}, depth int) error {
+	e.encodeString(key)
+	e.out.WriteByte(':')
+	if e.indent != "" {
+		e.out.WriteByte(' ')
+	}
+	return e.encode(value, depth+1)
+}
+
+func (e *jsonEncoder) encodeString(s string) {
+	encoder := json.NewEncoder(&e.out)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	// Encode terminates the value with a newline.
+	e.out.Truncate(e.out.Len() - 1)
+}
+
+func (e *jsonEncoder) newline(depth int) {
+	if e.indent == "" {
+		return
+	}
+	e.out.WriteByte('\n')
+	e.out.WriteString(strings.Repeat(e.indent, depth))
+}
@@ -2,17 +2,39 @@ package lox
 
 import "fmt"
 
+// LoxClass is itself an object: its Metaclass holds the class methods and
+// its Fields hold the static fields. Metaclasses inherit from the metaclass
+// of the superclass, so static members are inherited like instance methods.
+// Getters and Setters run when an instance property of their name is read or
+// assigned, and Finals are the fields that only 'init' may assign.
 type LoxClass struct {
 	Name       string
 	Superclass *LoxClass
-	Methods    map[string]LoxFunction
+	Methods    map[string]*LoxFunction
+	Getters    map[string]*LoxFunction
+	Setters    map[string]*LoxFunction
+	Finals     map[string]bool
+	Metaclass  *LoxClass
+	Fields     map[string]interface{}
 }
 
-func NewLoxClass(name string, superclass *LoxClass, methods map[string]LoxFunction) *LoxClass {
+func NewLoxClass(name string, superclass *LoxClass, methods map[string]*LoxFunction, classMethods map[string]*LoxFunction) *LoxClass {
+	var superMetaclass *LoxClass
+	if superclass != nil {
+		superMetaclass = superclass.Metaclass
+	}
+
 	return &LoxClass{
 		Name:       name,
 		Superclass: superclass,
 		Methods:    methods,
+		Metaclass: &LoxClass{
+			Name:       name + " metaclass",
+			Superclass: superMetaclass,
+			Methods:    classMethods,
+		},
+		Finals: map[string]bool{},
+		Fields: map[string]interface{}{},
 	}
 }
 
@@ -21,29 +43,89 @@ func (c LoxClass) String() string {
 }
 
 func (c *LoxClass) Call(interpreter *Interpreter, arguments []interface{}) interface{} {
-	loxInstance := NewLoxInstance(*c)
+	loxInstance := NewLoxInstance(c)
 	initializer := c.FindMethod("init")
 	if initializer != nil {
 		initializer.Bind(loxInstance).Call(interpreter, arguments)
 	}
+	loxInstance.initialized = true
 	return loxInstance
 }
 
-func (c *LoxClass) Arity() int {
+func (c *LoxClass) ParameterNames() []string {
+	initializer := c.FindMethod("init")
+	if initializer == nil {
+		return nil
+	}
+	return initializer.ParameterNames()
+}
+
+func (c *LoxClass) MinArity() int {
 	initializer := c.FindMethod("init")
 	if initializer == nil {
 		return 0
 	}
-	return initializer.Arity()
+	return initializer.MinArity()
+}
+
+func (c *LoxClass) MaxArity() int {
+	initializer := c.FindMethod("init")
+	if initializer == nil {
+		return 0
+	}
+	return initializer.MaxArity()
+}
+
+func (c *LoxClass) Get(name Token) interface{} {
+	for class := c; class != nil; class = class.Superclass {
+		if f, ok := class.Fields[name.Lexeme]; ok {
+			return f
+		}
+	}
+
+	method := c.Metaclass.FindMethod(name.Lexeme)
+	if method != nil {
+		return method.Bind(c)
+	}
+
+	panic(NewRuntimeError(name, fmt.Sprintf("undefined property %v .", name.Lexeme)))
+}
+
+func (c *LoxClass) Set(name Token, value interface{}) {
+	c.Fields[name.Lexeme] = value
+}
+
+// IsFinal reports whether c or one of its superclasses declares name final.
+func (c *LoxClass) IsFinal(name string) bool {
+	for class := c; class != nil; class = class.Superclass {
+		if class.Finals[name] {
+			return true
+		}
+	}
+	return false
 }
 
 func (c *LoxClass) FindMethod(name string) *LoxFunction {
-	if f, ok := c.Methods[name]; ok {
-		return &f
+	return c.findMember(name, func(class *LoxClass) map[string]*LoxFunction { return class.Methods })
+}
+
+func (c *LoxClass) FindGetter(name string) *LoxFunction {
+	return c.findMember(name, func(class *LoxClass) map[string]*LoxFunction { return class.Getters })
+}
+
+func (c *LoxClass) FindSetter(name string) *LoxFunction {
+	return c.findMember(name, func(class *LoxClass) map[string]*LoxFunction { return class.Setters })
+}
+
+// findMember looks name up in the members of c selected by members, then in
+// those of its superclasses.
+func (c *LoxClass) findMember(name string, members func(*LoxClass) map[string]*LoxFunction) *LoxFunction {
+	if f, ok := members(c)[name]; ok {
+		return f
 	}
 
 	if c.Superclass != nil {
-		return c.Superclass.FindMethod(name)
+		return c.Superclass.findMember(name, members)
 	}
 
 	return nil
@@ -0,0 +1,35 @@
+package lox
+
+import "fmt"
+
+// LoxException is the value a catch clause receives for runtime errors and
+// for errors created with the Error native.
+type LoxException struct {
+	Message string
+	Line    int
+	Stack   string
+}
+
+func NewLoxException(message string, line int, stack string) *LoxException {
+	return &LoxException{
+		Message: message,
+		Line:    line,
+		Stack:   stack,
+	}
+}
+
+func (e LoxException) String() string {
+	return e.Message
+}
+
+func (e *LoxException) Get(name Token) interface{} {
+	switch name.Lexeme {
+	case "message":
+		return e.Message
+	case "line":
+		return float64(e.Line)
+	case "stack":
+		return e.Stack
+	}
+	panic(NewRuntimeError(name, fmt.Sprintf("undefined property %v .", name.Lexeme)))
+}
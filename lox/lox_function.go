@@ -2,25 +2,37 @@ package lox
 
 import "fmt"
 
+// LoxFunction is a function or method. A bound method also remembers the
+// method it was bound from and its receiver, so that binding the same method
+// to the same object twice gives equal values. Globals are looked up in the
+// globals of the script or module that declared the function.
 type LoxFunction struct {
 	Declaration   Function
 	Closure       *Environment
+	globals       *Environment
 	isInitializer bool
+	method        *LoxFunction
+	receiver      interface{}
 }
 
 func NewLoxFunction(declaration Function, closure *Environment, isInitializer bool) *LoxFunction {
 	return &LoxFunction{
 		Declaration:   declaration,
 		Closure:       closure,
+		globals:       closure.global(),
 		isInitializer: isInitializer,
 	}
 }
 
 func (f *LoxFunction) Call(interpreter *Interpreter, arguments []interface{}) (retVal interface{}) {
+	previousGlobals := interpreter.globals
+	interpreter.globals = f.globals
+	defer func() {
+		interpreter.globals = previousGlobals
+	}()
+
 	environment := NewEnvironmentWithEnclosing(f.Closure)
-	for i, param := range f.Declaration.Params {
-		environment.Define(param.Lexeme, arguments[i])
-	}
+	f.bindParameters(interpreter, environment, arguments)
 
 	defer func() {
 		val := recover()
@@ -46,7 +58,54 @@ func (f *LoxFunction) Call(interpreter *Interpreter, arguments []interface{}) (r
 	return nil
 }
 
-func (f *LoxFunction) Arity() int {
+// bindParameters defines the parameters in the environment of a call.
+// Defaults of missing arguments are evaluated in that environment, so they
+// can refer to the parameters before them.
+func (f *LoxFunction) bindParameters(interpreter *Interpreter, environment *Environment, arguments []interface{}) {
+	previous := interpreter.environment
+	defer func() {
+		interpreter.environment = previous
+	}()
+	interpreter.environment = environment
+
+	for i, param := range f.Declaration.Params {
+		if i < len(arguments) && arguments[i] != missingArgument {
+			environment.Define(param.Lexeme, arguments[i])
+		} else {
+			environment.Define(param.Lexeme, interpreter.evaluate(f.Declaration.Defaults[i]))
+		}
+	}
+
+	if f.Declaration.Rest != nil {
+		var rest []interface{}
+		if len(arguments) > len(f.Declaration.Params) {
+			rest = append(rest, arguments[len(f.Declaration.Params):]...)
+		}
+		environment.Define(f.Declaration.Rest.Lexeme, NewLoxList(rest))
+	}
+}
+
+func (f *LoxFunction) ParameterNames() []string {
+	names := make([]string, len(f.Declaration.Params))
+	for i, param := range f.Declaration.Params {
+		names[i] = param.Lexeme
+	}
+	return names
+}
+
+func (f *LoxFunction) MinArity() int {
+	for i, def := range f.Declaration.Defaults {
+		if def != nil {
+			return i
+		}
+	}
+	return len(f.Declaration.Params)
+}
+
+func (f *LoxFunction) MaxArity() int {
+	if f.Declaration.Rest != nil {
+		return VariadicArity
+	}
 	return len(f.Declaration.Params)
 }
 
@@ -54,8 +113,22 @@ func (f LoxFunction) String() string {
 	return fmt.Sprintf("<fn %v>", f.Declaration.Name.Lexeme)
 }
 
-func (f *LoxFunction) Bind(instance *LoxInstance) *LoxFunction {
+// Bind returns a copy of f with 'this' bound to object, which is an instance
+// for methods and the class itself for class methods.
+func (f *LoxFunction) Bind(object interface{}) *LoxFunction {
 	environment := NewEnvironmentWithEnclosing(f.Closure)
-	environment.Define("this", instance)
-	return NewLoxFunction(f.Declaration, environment, f.isInitializer)
+	environment.Define("this", object)
+	bound := NewLoxFunction(f.Declaration, environment, f.isInitializer)
+	bound.method = f
+	bound.receiver = object
+	return bound
+}
+
+// Equal reports whether f and other are the same function or the same method
+// bound to the same receiver.
+func (f *LoxFunction) Equal(other *LoxFunction) bool {
+	if f.method == nil || other.method == nil {
+		return f == other
+	}
+	return f.method == other.method && f.receiver == other.receiver
 }
@@ -2,12 +2,26 @@ package lox
 
 import "fmt"
 
+// Getter is implemented by runtime values whose properties can be read with
+// the '.' operator.
+type Getter interface {
+	Get(name Token) interface{}
+}
+
+// Setter is implemented by runtime values whose properties can also be
+// assigned with the '.' operator.
+type Setter interface {
+	Getter
+	Set(name Token, value interface{})
+}
+
 type LoxInstance struct {
-	Class  LoxClass
-	Fields map[string]interface{}
+	Class       *LoxClass
+	Fields      map[string]interface{}
+	initialized bool
 }
 
-func NewLoxInstance(class LoxClass) *LoxInstance {
+func NewLoxInstance(class *LoxClass) *LoxInstance {
 	return &LoxInstance{
 		Class:  class,
 		Fields: map[string]interface{}{},
@@ -32,5 +46,40 @@ func (i *LoxInstance) Get(name Token) interface{} {
 }
 
 func (i *LoxInstance) Set(name Token, value interface{}) {
+	i.checkAssignable(name)
 	i.Fields[name.Lexeme] = value
 }
+
+// checkAssignable rejects assigning a final field once init has returned.
+func (i *LoxInstance) checkAssignable(name Token) {
+	if i.initialized && i.Class.IsFinal(name.Lexeme) {
+		panic(NewRuntimeError(name, fmt.Sprintf("can't assign to final field '%v' outside of init.", name.Lexeme)))
+	}
+}
+
+// GetProperty is like Get, but a getter declared for name takes precedence
+// over methods and is run to produce the value.
+func (i *LoxInstance) GetProperty(interpreter *Interpreter, name Token) interface{} {
+	if f, ok := i.Fields[name.Lexeme]; ok {
+		return f
+	}
+
+	getter := i.Class.FindGetter(name.Lexeme)
+	if getter != nil {
+		return getter.Bind(i).Call(interpreter, nil)
+	}
+
+	return i.Get(name)
+}
+
+// SetProperty is like Set, but passes the value to a setter declared for
+// name instead of storing it in a field.
+func (i *LoxInstance) SetProperty(interpreter *Interpreter, name Token, value interface{}) {
+	setter := i.Class.FindSetter(name.Lexeme)
+	if setter != nil {
+		setter.Bind(i).Call(interpreter, []interface{}{value})
+		return
+	}
+
+	i.Set(name, value)
+}
@@ -0,0 +1,91 @@
+package lox
+
+import (
+	"fmt"
+	"strings"
+)
+
+type LoxList struct {
+	Elements []interface{}
+}
+
+func NewLoxList(elements []interface{}) *LoxList {
+	return &LoxList{
+		Elements: elements,
+	}
+}
+
+func (l *LoxList) String() string {
+	return l.format(stringify, map[interface{}]bool{})
+}
+
+// format writes the list using stringify to convert its elements. The lists
+// and maps in visiting are being written already, so a list containing
+// itself is written as [...] there.
+func (l *LoxList) format(stringify func(interface{}) string, visiting map[interface{}]bool) string {
+	if visiting[l] {
+		return "[...]"
+	}
+	visiting[l] = true
+	defer delete(visiting, l)
+
+	parts := make([]string, len(l.Elements))
+	for i, e := range l.Elements {
+		parts[i] = formatElement(e, stringify, visiting)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+// formatElement writes an element of a list or map, keeping track of the
+// lists and maps being written.
+func formatElement(value interface{}, stringify func(interface{}) string, visiting map[interface{}]bool) string {
+	switch v := value.(type) {
+	case *LoxList:
+		return v.format(stringify, visiting)
+	case *LoxMap:
+		return v.format(stringify, visiting)
+	}
+	return stringify(value)
+}
+
+func (l *LoxList) Get(name Token) interface{} {
+	switch name.Lexeme {
+	case "len":
+		return NewProtoCallable(0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return float64(len(l.Elements))
+		})
+	case "get":
+		return NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return l.Elements[l.index(interpreter, "get", arguments)]
+		})
+	case "set":
+		return NewProtoCallable(2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			l.Elements[l.index(interpreter, "set", arguments)] = arguments[1]
+			return arguments[1]
+		})
+	case "push":
+		return NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			l.Elements = append(l.Elements, arguments[0])
+			return nil
+		})
+	case "pop":
+		return NewProtoCallable(0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			if len(l.Elements) == 0 {
+				interpreter.nativeError("pop: list is empty.")
+			}
+			last := l.Elements[len(l.Elements)-1]
+			l.Elements = l.Elements[:len(l.Elements)-1]
+			return last
+		})
+	}
+	panic(NewRuntimeError(name, fmt.Sprintf("undefined property %v .", name.Lexeme)))
+}
+
+// index validates the index passed as the first argument of a list method.
+func (l *LoxList) index(interpreter *Interpreter, name string, arguments []interface{}) int {
+	index := interpreter.integerArgument(name, arguments, 0)
+	if index < 0 || index >= len(l.Elements) {
+		interpreter.nativeError("%v: index %v out of range for list of length %v.", name, index, len(l.Elements))
+	}
+	return index
+}
@@ -0,0 +1,105 @@
+package lox
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LoxMap maps strings to values and remembers the insertion order of its keys.
+type LoxMap struct {
+	Keys    []string
+	Entries map[string]interface{}
+}
+
+func NewLoxMap() *LoxMap {
+	return &LoxMap{
+		Keys:    []string{},
+		Entries: map[string]interface{}{},
+	}
+}
+
+func (m *LoxMap) String() string {
+	return m.format(stringify, map[interface{}]bool{})
+}
+
+// format writes the map like LoxList.format, using {...} for a map that
+// contains itself.
+func (m *LoxMap) format(stringify func(interface{}) string, visiting map[interface{}]bool) string {
+	if visiting[m] {
+		return "{...}"
+	}
+	visiting[m] = true
+	defer delete(visiting, m)
+
+	parts := make([]string, len(m.Keys))
+	for i, key := range m.Keys {
+		parts[i] = fmt.Sprintf("%v: %v", key, formatElement(m.Entries[key], stringify, visiting))
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+func (m *LoxMap) Put(key string, value interface{}) {
+	if _, ok := m.Entries[key]; !ok {
+		m.Keys = append(m.Keys, key)
+	}
+	m.Entries[key] = value
+}
+
+func (m *LoxMap) Remove(key string) {
+	if _, ok := m.Entries[key]; !ok {
+		return
+	}
+	delete(m.Entries, key)
+	for i, k := range m.Keys {
+		if k == key {
+			m.Keys = append(m.Keys[:i], m.Keys[i+1:]...)
+			break
+		}
+	}
+}
+
+func (m *LoxMap) Get(name Token) interface{} {
+	switch name.Lexeme {
+	case "len":
+		return NewProtoCallable(0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return float64(len(m.Keys))
+		})
+	case "get":
+		// get returns nil for missing keys.
+		return NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return m.Entries[interpreter.stringArgument("get", arguments, 0)]
+		})
+	case "set":
+		return NewProtoCallable(2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			m.Put(interpreter.stringArgument("set", arguments, 0), arguments[1])
+			return arguments[1]
+		})
+	case "has":
+		return NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			_, ok := m.Entries[interpreter.stringArgument("has", arguments, 0)]
+			return ok
+		})
+	case "remove":
+		return NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			m.Remove(interpreter.stringArgument("remove", arguments, 0))
+			return nil
+		})
+	case "keys":
+		return NewProtoCallable(0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			elements := make([]interface{}, len(m.Keys))
+			for i, key := range m.Keys {
+				elements[i] = key
+			}
+			return NewLoxList(elements)
+		})
+	case "values":
+		return NewProtoCallable(0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			elements := make([]interface{}, len(m.Keys))
+			for i, key := range m.Keys {
+				elements[i] = m.Entries[key]
+			}
+			return NewLoxList(elements)
+		})
+	}
+	panic(NewRuntimeError(name, fmt.Sprintf("undefined property %v .", name.Lexeme)))
+}
@@ -0,0 +1,20 @@
+package lox
+
+import "fmt"
+
+// LoxTrait is a named set of methods that classes include with 'with'.
+type LoxTrait struct {
+	Name    string
+	Methods map[string]*LoxFunction
+}
+
+func NewLoxTrait(name string, methods map[string]*LoxFunction) *LoxTrait {
+	return &LoxTrait{
+		Name:    name,
+		Methods: methods,
+	}
+}
+
+func (t LoxTrait) String() string {
+	return fmt.Sprintf("%v", t.Name)
+}
@@ -0,0 +1,77 @@
+package lox
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+func newMathModule() *LoxModule {
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	values := map[string]interface{}{
+		"pi":  math.Pi,
+		"e":   math.E,
+		"inf": math.Inf(1),
+	}
+
+	unary := map[string]func(float64) float64{
+		"floor": math.Floor,
+		"ceil":  math.Ceil,
+		"round": math.Round,
+		"abs":   math.Abs,
+		"sqrt":  math.Sqrt,
+		"sin":   math.Sin,
+		"cos":   math.Cos,
+		"tan":   math.Tan,
+		"log":   math.Log,
+		"exp":   math.Exp,
+	}
+	for name, fn := range unary {
+		name, fn := name, fn
+		values[name] = NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return fn(interpreter.numberArgument(name, arguments, 0))
+		})
+	}
+
+	values["pow"] = NewProtoCallable(2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		return math.Pow(interpreter.numberArgument("pow", arguments, 0), interpreter.numberArgument("pow", arguments, 1))
+	})
+
+	values["min"] = NewVariadicProtoCallable(1, VariadicArity, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		return extremum(interpreter, "min", arguments, math.Min)
+	})
+
+	values["max"] = NewVariadicProtoCallable(1, VariadicArity, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		return extremum(interpreter, "max", arguments, math.Max)
+	})
+
+	values["seed"] = NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		random.Seed(int64(interpreter.integerArgument("seed", arguments, 0)))
+		return nil
+	})
+
+	values["random"] = NewProtoCallable(0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		return random.Float64()
+	})
+
+	// randomInt returns an integer between its arguments, both inclusive.
+	values["randomInt"] = NewProtoCallable(2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		low := interpreter.integerArgument("randomInt", arguments, 0)
+		high := interpreter.integerArgument("randomInt", arguments, 1)
+		if low > high {
+			interpreter.nativeError("randomInt: lower bound %v is greater than upper bound %v.", low, high)
+		}
+		return float64(low + random.Intn(high-low+1))
+	}).WithParams("low", "high")
+
+	return NewLoxModule("math", "", values)
+}
+
+func extremum(interpreter *Interpreter, name string, arguments []interface{}, pick func(float64, float64) float64) interface{} {
+	result := interpreter.numberArgument(name, arguments, 0)
+	for j := range arguments[1:] {
+		result = pick(result, interpreter.numberArgument(name, arguments, j+1))
+	}
+	return result
+}
@@ -0,0 +1,161 @@
+package lox
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoxModule holds the top-level declarations of an imported file.
+type LoxModule struct {
+	Name   string
+	Path   string
+	Values map[string]interface{}
+}
+
+func NewLoxModule(name string, path string, values map[string]interface{}) *LoxModule {
+	nameNatives(values)
+	return &LoxModule{
+		Name:   name,
+		Path:   path,
+		Values: values,
+	}
+}
+
+func (m LoxModule) String() string {
+	return fmt.Sprintf("<module %v>", m.Name)
+}
+
+func (m *LoxModule) Get(name Token) interface{} {
+	if v, ok := m.Values[name.Lexeme]; ok {
+		return v
+	}
+	panic(NewRuntimeError(name, fmt.Sprintf("module '%v' has no member '%v'.", m.Name, name.Lexeme)))
+}
+
+// SetScriptPath sets the file of the main script. Imports in the script are
+// resolved relative to its directory, and importing the script itself is an
+// import cycle.
+func (i *Interpreter) SetScriptPath(path string) {
+	i.scriptPath = path
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	i.loading = []string{path}
+}
+
+// SetSearchPath sets the directories searched for modules that are not found
+// relative to the importing file.
+func (i *Interpreter) SetSearchPath(paths []string) {
+	i.searchPath = paths
+}
+
+// defineModule makes a native module available as a global and to imports
+// by its name.
+func (i *Interpreter) defineModule(module *LoxModule) {
+	i.builtins.Define(module.Name, module)
+	i.modules[module.Name] = module
+}
+
+// importModule returns the module at path, executing it on first import.
+func (i *Interpreter) importModule(keyword Token, path string) *LoxModule {
+	if module, ok := i.modules[path]; ok {
+		return module
+	}
+
+	resolved, ok := i.findModule(path)
+	if !ok {
+		panic(NewRuntimeError(keyword, fmt.Sprintf("can't find module '%v'.", path)))
+	}
+
+	if module, ok := i.modules[resolved]; ok {
+		return module
+	}
+
+	for j, loading := range i.loading {
+		if loading == resolved {
+			chain := append(append([]string{}, i.loading[j:]...), resolved)
+			panic(NewRuntimeError(keyword, fmt.Sprintf("import cycle: %v.", strings.Join(chain, " -> "))))
+		}
+	}
+
+	module := i.runModule(keyword, resolved)
+	i.modules[resolved] = module
+	return module
+}
+
+func (i *Interpreter) findModule(path string) (string, bool) {
+	if filepath.Ext(path) == "" {
+		path += ".lox"
+	}
+
+	var candidates []string
+	if filepath.IsAbs(path) {
+		candidates = append(candidates, path)
+	} else {
+		dir := "."
+		if i.scriptPath != "" {
+			dir = filepath.Dir(i.scriptPath)
+		}
+		candidates = append(candidates, filepath.Join(dir, path))
+		for _, dir := range i.searchPath {
+			candidates = append(candidates, filepath.Join(dir, path))
+		}
+	}
+
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			abs, err := filepath.Abs(candidate)
+			if err != nil {
+				return candidate, true
+			}
+			return abs, true
+		}
+	}
+	return "", false
+}
+
+// runModule executes the file at path with its own globals and collects them
+// into a module.
+func (i *Interpreter) runModule(keyword Token, path string) *LoxModule {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		panic(NewRuntimeError(keyword, fmt.Sprintf("can't read module '%v': %v.", path, err)))
+	}
+
+	scanner := NewScanner(string(source))
+	tokens, err := scanner.ScanTokens()
+	if err == nil {
+		var statements []Stmt
+		statements, err = NewParser(tokens).Parse()
+		if err == nil {
+			resolver := NewResolver(i)
+			resolver.IgnoreWarnings(scanner.IgnoredWarnings())
+			err = resolver.ResolveStatements(statements)
+		}
+		if err == nil {
+			return i.executeModule(path, statements)
+		}
+	}
+	panic(NewRuntimeError(keyword, fmt.Sprintf("error in module '%v'.", path)))
+}
+
+func (i *Interpreter) executeModule(path string, statements []Stmt) *LoxModule {
+	previousGlobals, previousEnvironment, previousPath := i.globals, i.environment, i.scriptPath
+	defer func() {
+		i.globals, i.environment, i.scriptPath = previousGlobals, previousEnvironment, previousPath
+		i.loading = i.loading[:len(i.loading)-1]
+	}()
+
+	env := NewEnvironmentWithEnclosing(i.builtins)
+	i.globals, i.environment, i.scriptPath = env, env, path
+	i.loading = append(i.loading, path)
+
+	for _, s := range statements {
+		i.execute(s)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return NewLoxModule(name, path, env.Values)
+}
@@ -0,0 +1,149 @@
+package lox
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// expectScriptOutput writes files to a temporary directory and runs the
+// main.lox among them. Paths in the output are relative to that directory.
+func expectScriptOutput(t *testing.T, files map[string]string, expected ...string) {
+	t.Helper()
+
+	dir := writeFiles(t, files)
+	interpreter := NewInterpreter()
+	interpreter.SetScriptPath(filepath.Join(dir, "main.lox"))
+
+	want := strings.Join(expected, "\n") + "\n"
+	output := interpret(t, interpreter, files["main.lox"])
+	if output = strings.ReplaceAll(output, dir+string(filepath.Separator), ""); output != want {
+		t.Errorf("got output\n%vwant\n%v", output, want)
+	}
+}
+
+// writeFiles writes files, named by their slash-separated path, to a
+// temporary directory and returns the directory.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, source := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestImport(t *testing.T) {
+	expectScriptOutput(t, map[string]string{
+		"lib/shapes.lox": `
+import { square } from "math2";
+var name = "shapes";
+fun area(side) { return square(side); }
+`,
+		"lib/math2.lox": `
+fun square(x) { return x * x; }
+`,
+		"main.lox": `
+import "lib/shapes.lox" as shapes;
+import { area, name } from "lib/shapes";
+print shapes.name;
+print shapes.area(3);
+print area(2) + 1;
+print name;
+print shapes;
+`,
+	}, "shapes", "9.000000", "5.000000", "shapes", "<module shapes>")
+}
+
+func TestImportRunsModuleOnce(t *testing.T) {
+	expectScriptOutput(t, map[string]string{
+		"counter.lox": `
+print "loading counter";
+var count = 0;
+fun next() { count = count + 1; return count; }
+`,
+		"other.lox": `
+import "counter" as counter;
+fun next() { return counter.next(); }
+`,
+		"main.lox": `
+import "counter" as a;
+import "other" as other;
+import "counter.lox" as b;
+print a.next();
+print other.next();
+print b.next();
+print a == b;
+`,
+	}, "loading counter", "1.000000", "2.000000", "3.000000", "true")
+}
+
+func TestImportErrors(t *testing.T) {
+	expectScriptOutput(t, map[string]string{
+		"lib.lox": `var x = 1;`,
+		"main.lox": `
+try { import "missing" as missing; } catch (e) { print e.message; }
+try { import { y } from "lib"; } catch (e) { print e.message; }
+`,
+	}, "can't find module 'missing'.", "module 'lib' has no member 'y'.")
+}
+
+func TestImportSearchPath(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"vendor/greet.lox": `fun hello(name) { return "hello " + name; }`,
+	})
+	interpreter := NewInterpreter()
+	interpreter.SetScriptPath(filepath.Join(t.TempDir(), "main.lox"))
+	interpreter.SetSearchPath([]string{filepath.Join(dir, "vendor")})
+
+	output := interpret(t, interpreter, `
+import { hello } from "greet";
+print hello("lox");
+`)
+	if output != "hello lox\n" {
+		t.Errorf("got output %q, want %q", output, "hello lox\n")
+	}
+}
+
+func TestModuleGlobals(t *testing.T) {
+	expectScriptOutput(t, map[string]string{
+		"lib.lox": `
+var counter = 0;
+fun helper() { return "lib " + str(counter); }
+fun inc() { counter = counter + 1; return helper(); }
+`,
+		"main.lox": `
+import "lib" as lib;
+print lib.inc();
+var counter = 100;
+fun helper() { return "main"; }
+print lib.inc();
+print counter;
+`,
+	}, "lib 1.000000", "lib 2.000000", "100.000000")
+}
+
+func TestImportCycle(t *testing.T) {
+	expectScriptOutput(t, map[string]string{
+		"a.lox": `
+print "a start";
+import "main" as main;
+`,
+		"main.lox": `
+print "main start";
+try {
+  import "a" as a;
+} catch (e) {
+  print e.message;
+}
+`,
+	}, "main start", "a start", "import cycle: main.lox -> a.lox -> main.lox.")
+}
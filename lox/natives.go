@@ -0,0 +1,36 @@
+package lox
+
+import "fmt"
+
+// nativeError reports a runtime error at the call of the native that is
+// currently executing.
+func (i *Interpreter) nativeError(format string, a ...interface{}) {
+	var paren Token
+	if len(i.callStack) > 0 {
+		paren = i.callStack[len(i.callStack)-1].paren
+	}
+	panic(NewRuntimeError(paren, fmt.Sprintf(format, a...)))
+}
+
+func (i *Interpreter) numberArgument(name string, arguments []interface{}, index int) float64 {
+	v, ok := arguments[index].(float64)
+	if !ok {
+		i.nativeError("%v: argument %v must be a number.", name, index+1)
+	}
+	return v
+}
+
+func (i *Interpreter) integerArgument(name string, arguments []interface{}, index int) int {
+	if !isInteger(arguments[index]) {
+		i.nativeError("%v: argument %v must be an integer.", name, index+1)
+	}
+	return int(arguments[index].(float64))
+}
+
+func (i *Interpreter) stringArgument(name string, arguments []interface{}, index int) string {
+	v, ok := arguments[index].(string)
+	if !ok {
+		i.nativeError("%v: argument %v must be a string.", name, index+1)
+	}
+	return v
+}
@@ -0,0 +1,106 @@
+package lox
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// ExitError is returned by Interpret when the script calls os.exit.
+type ExitError struct {
+	Code int
+}
+
+func (err *ExitError) Error() string {
+	return fmt.Sprintf("exit status %v", err.Code)
+}
+
+// SetArgs sets the script arguments exposed as os.args.
+func (i *Interpreter) SetArgs(args []string) {
+	elements := make([]interface{}, len(args))
+	for j, arg := range args {
+		elements[j] = arg
+	}
+	i.modules["os"].Values["args"] = NewLoxList(elements)
+}
+
+func newOSModule() *LoxModule {
+	values := map[string]interface{}{
+		"args": NewLoxList([]interface{}{}),
+	}
+
+	// env returns nil for unset variables.
+	values["env"] = NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		interpreter.requireCapability(CAP_ENV, "env", "environment access")
+		value, ok := os.LookupEnv(interpreter.stringArgument("env", arguments, 0))
+		if !ok {
+			return nil
+		}
+		return value
+	})
+
+	values["setEnv"] = NewProtoCallable(2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		interpreter.requireCapability(CAP_ENV, "setEnv", "environment access")
+		name := interpreter.stringArgument("setEnv", arguments, 0)
+		if err := os.Setenv(name, interpreter.stringArgument("setEnv", arguments, 1)); err != nil {
+			interpreter.nativeError("setEnv: %v.", err)
+		}
+		return nil
+	})
+
+	values["cwd"] = NewProtoCallable(0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		interpreter.requireCapability(CAP_ENV, "cwd", "environment access")
+		dir, err := os.Getwd()
+		if err != nil {
+			interpreter.nativeError("cwd: %v.", err)
+		}
+		return dir
+	})
+
+	values["exit"] = NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		interpreter.requireCapability(CAP_EXIT, "exit", "exiting")
+		panic(&ExitError{Code: interpreter.integerArgument("exit", arguments, 0)})
+	})
+
+	// exec runs a command with a list of arguments and returns a map with its
+	// stdout, stderr and exit code.
+	values["exec"] = NewProtoCallable(2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		interpreter.requireCapability(CAP_EXEC, "exec", "running commands")
+		name := interpreter.stringArgument("exec", arguments, 0)
+		list, ok := arguments[1].(*LoxList)
+		if !ok {
+			interpreter.nativeError("exec: argument 2 must be a list.")
+		}
+		args := make([]string, len(list.Elements))
+		for j, arg := range list.Elements {
+			s, ok := arg.(string)
+			if !ok {
+				interpreter.nativeError("exec: command arguments must be strings.")
+			}
+			args[j] = s
+		}
+
+		var stdout, stderr bytes.Buffer
+		cmd := exec.Command(name, args...)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		code := 0
+		if err := cmd.Run(); err != nil {
+			var exitErr *exec.ExitError
+			if !errors.As(err, &exitErr) {
+				interpreter.nativeError("exec: %v.", err)
+			}
+			code = exitErr.ExitCode()
+		}
+
+		result := NewLoxMap()
+		result.Put("stdout", stdout.String())
+		result.Put("stderr", stderr.String())
+		result.Put("code", float64(code))
+		return result
+	})
+
+	return NewLoxModule("os", "", values)
+}
@@ -0,0 +1,105 @@
+package lox
+
+import "fmt"
+
+// binaryOperatorMethods maps the binary operators a class can overload to the
+// name of the method implementing them. '!=' negates the result of '__eq__'.
+var binaryOperatorMethods = map[TokenType]string{
+	PLUS:            "__add__",
+	MINUS:           "__sub__",
+	STAR:            "__mul__",
+	SLASH:           "__div__",
+	PERCENT:         "__mod__",
+	TILDE_SLASH:     "__floordiv__",
+	STAR_STAR:       "__pow__",
+	AMPERSAND:       "__and__",
+	PIPE:            "__or__",
+	CARET:           "__xor__",
+	LESS_LESS:       "__lshift__",
+	GREATER_GREATER: "__rshift__",
+	EQUAL_EQUAL:     "__eq__",
+	BANG_EQUAL:      "__eq__",
+	LESS:            "__lt__",
+	LESS_EQUAL:      "__le__",
+	GREATER:         "__gt__",
+	GREATER_EQUAL:   "__ge__",
+}
+
+var unaryOperatorMethods = map[TokenType]string{
+	MINUS: "__neg__",
+	TILDE: "__invert__",
+}
+
+// binary evaluates a binary operator, calling the left operand's operator
+// method when it is an instance whose class defines one.
+func (i *Interpreter) binary(operator Token, left interface{}, right interface{}) interface{} {
+	if instance, ok := left.(*LoxInstance); ok {
+		if method := instance.Class.FindMethod(binaryOperatorMethods[operator.TokenType]); method != nil {
+			result := i.callMethod(instance, method, operator, right)
+			if operator.TokenType == BANG_EQUAL {
+				return !isTruthy(result)
+			}
+			return result
+		}
+	}
+	return binaryOperation(operator, left, right)
+}
+
+// unary returns the result of the operand's operator method, and false when
+// the operand doesn't overload operator.
+func (i *Interpreter) unary(operator Token, operand interface{}) (interface{}, bool) {
+	instance, ok := operand.(*LoxInstance)
+	if !ok {
+		return nil, false
+	}
+	method := instance.Class.FindMethod(unaryOperatorMethods[operator.TokenType])
+	if method == nil {
+		return nil, false
+	}
+	return i.callMethod(instance, method, operator), true
+}
+
+// callMethod calls method bound to instance on behalf of the operator or
+// call at token, recording it on the call stack like a regular call.
+func (i *Interpreter) callMethod(instance *LoxInstance, method *LoxFunction, token Token, arguments ...interface{}) interface{} {
+	bound := method.Bind(instance)
+	checkArity(token, bound, len(arguments))
+
+	i.callStack = append(i.callStack, callFrame{callee: bound, paren: token})
+	value := bound.Call(i, arguments)
+	i.callStack = i.callStack[:len(i.callStack)-1]
+	return value
+}
+
+// stringify is like the stringify function, but converts instances using
+// the '__str__' method of their class, including inside lists and maps.
+func (i *Interpreter) stringify(object interface{}) string {
+	switch v := object.(type) {
+	case *LoxInstance:
+		method := v.Class.FindMethod("__str__")
+		if method == nil {
+			break
+		}
+		s, ok := i.callMethod(v, method, method.Declaration.Name).(string)
+		if !ok {
+			panic(NewRuntimeError(method.Declaration.Name, fmt.Sprintf("__str__ of %v must return a string.", v.Class.Name)))
+		}
+		return s
+	case *LoxList:
+		return v.format(i.stringify, map[interface{}]bool{})
+	case *LoxMap:
+		return v.format(i.stringify, map[interface{}]bool{})
+	}
+	return stringify(object)
+}
+
+// safeStringify is like stringify, but falls back to the plain conversion
+// when '__str__' fails, for reporting errors that are already unwinding.
+func (i *Interpreter) safeStringify(object interface{}) (s string) {
+	defer func() {
+		if val := recover(); val != nil {
+			s = stringify(object)
+		}
+	}()
+	return i.stringify(object)
+}
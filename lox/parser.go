@@ -1,6 +1,9 @@
 package lox
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 type Parser struct {
 	tokens  []Token
@@ -21,6 +24,9 @@ func (p *Parser) Parse() ([]Stmt, error) {
 	for !p.isAtEnd() {
 		statements = append(statements, p.declaration())
 	}
+	if p.hadError {
+		return nil, errors.New("error in parser")
+	}
 	return statements, nil
 }
 
@@ -38,6 +44,10 @@ func (p *Parser) declaration() Stmt {
 		return p.classDeclaration()
 	}
 
+	if p.match(TRAIT) {
+		return p.traitDeclaration()
+	}
+
 	if p.match(FUN) {
 		return p.function("function")
 	}
@@ -46,6 +56,14 @@ func (p *Parser) declaration() Stmt {
 		return p.varDeclaration()
 	}
 
+	if p.match(CONST) {
+		return p.constDeclaration()
+	}
+
+	if p.match(IMPORT) {
+		return p.importDeclaration()
+	}
+
 	return p.statement()
 }
 
@@ -58,27 +76,92 @@ func (p *Parser) classDeclaration() Stmt {
 		superclass = NewVariable(p.previous())
 	}
 
+	var traits []*Variable
+	if p.matchContextual("with") {
+		for {
+			p.consume(IDENTIFIER, "expect trait name.")
+			traits = append(traits, NewVariable(p.previous()))
+			if !p.match(COMMA) {
+				break
+			}
+		}
+	}
+
 	p.consume(LEFT_BRACE, "expect '{' before class body.")
 
+	var finals []Token
+	var methods, getters, setters, classMethods []Function
+	for !p.check(RIGHT_BRACE) && !p.isAtEnd() {
+		switch {
+		case p.matchContextual("final"):
+			for {
+				finals = append(finals, p.consume(IDENTIFIER, "expect field name."))
+				if !p.match(COMMA) {
+					break
+				}
+			}
+			p.consume(SEMICOLON, "expect ';' after final fields.")
+		case p.match(CLASS) || p.matchContextual("static"):
+			classMethods = append(classMethods, *(p.function("method")).(*Function))
+		case p.matchContextual("set"):
+			setter := p.function("setter").(*Function)
+			if len(setter.Params) != 1 || setter.Rest != nil {
+				p.reportError(setter.Name, "setter must take exactly one parameter.")
+			}
+			setters = append(setters, *setter)
+		case p.check(IDENTIFIER) && p.checkNext(LEFT_BRACE):
+			getters = append(getters, *p.getter())
+		default:
+			methods = append(methods, *(p.function("method")).(*Function))
+		}
+	}
+
+	p.consume(RIGHT_BRACE, "expect '}' after class body.")
+	return NewClass(name, superclass, traits, finals, methods, getters, setters, classMethods)
+}
+
+func (p *Parser) traitDeclaration() Stmt {
+	name := p.consume(IDENTIFIER, "expect trait name.")
+	p.consume(LEFT_BRACE, "expect '{' before trait body.")
+
 	var methods []Function
 	for !p.check(RIGHT_BRACE) && !p.isAtEnd() {
 		methods = append(methods, *(p.function("method")).(*Function))
 	}
 
-	p.consume(RIGHT_BRACE, "expect '}' after class body.")
-	return NewClass(name, superclass, methods)
+	p.consume(RIGHT_BRACE, "expect '}' after trait body.")
+	return NewTrait(name, methods)
 }
 
 func (p *Parser) function(kind string) Stmt {
 	name := p.consume(IDENTIFIER, fmt.Sprintf("expect %v name.", kind))
 	p.consume(LEFT_PAREN, fmt.Sprintf("expect '(' after %v name.", kind))
 	var parameters []Token
+	var defaults []Expr
+	var rest *Token
 	if !p.check(RIGHT_PAREN) {
 		for {
 			if len(parameters) >= 255 {
 				p.reportError(p.peek(), "can't have more than 255 parameters.")
 			}
-			parameters = append(parameters, p.consume(IDENTIFIER, "expect parameter name."))
+			if p.match(DOT_DOT_DOT) {
+				name := p.consume(IDENTIFIER, "expect rest parameter name after '...'.")
+				rest = &name
+				if p.check(COMMA) {
+					panic(NewLoxError(p.peek(), "rest parameter must be the last parameter."))
+				}
+				break
+			}
+
+			parameter := p.consume(IDENTIFIER, "expect parameter name.")
+			var def Expr
+			if p.match(EQUAL) {
+				def = p.expression()
+			} else if len(defaults) > 0 && defaults[len(defaults)-1] != nil {
+				p.reportError(parameter, "parameter without default value can't follow parameters with defaults.")
+			}
+			parameters = append(parameters, parameter)
+			defaults = append(defaults, def)
 			if !p.match(COMMA) {
 				break
 			}
@@ -88,7 +171,16 @@ func (p *Parser) function(kind string) Stmt {
 
 	p.consume(LEFT_BRACE, fmt.Sprintf("expect '{' before %v body.", kind))
 	body := p.blockStatement()
-	return NewFunction(name, parameters, body)
+	return NewFunction(name, parameters, defaults, rest, body)
+}
+
+// getter parses a method declared without a parameter list, which runs when
+// the property of the same name is read.
+func (p *Parser) getter() *Function {
+	name := p.consume(IDENTIFIER, "expect getter name.")
+	p.consume(LEFT_BRACE, "expect '{' before getter body.")
+	body := p.blockStatement()
+	return NewFunction(name, nil, nil, nil, body)
 }
 
 func (p *Parser) varDeclaration() Stmt {
@@ -100,7 +192,41 @@ func (p *Parser) varDeclaration() Stmt {
 	}
 
 	p.consume(SEMICOLON, "expect ';' after variable declaration.")
-	return NewVar(name, initializer)
+	return NewVar(name, initializer, false)
+}
+
+func (p *Parser) constDeclaration() Stmt {
+	name := p.consume(IDENTIFIER, "expect constant name.")
+	p.consume(EQUAL, "expect '=' after constant name.")
+	initializer := p.expression()
+
+	p.consume(SEMICOLON, "expect ';' after constant declaration.")
+	return NewVar(name, initializer, true)
+}
+
+func (p *Parser) importDeclaration() Stmt {
+	keyword := p.previous()
+
+	if p.match(LEFT_BRACE) {
+		var names []Token
+		for {
+			names = append(names, p.consume(IDENTIFIER, "expect imported name."))
+			if !p.match(COMMA) {
+				break
+			}
+		}
+		p.consume(RIGHT_BRACE, "expect '}' after imported names.")
+		p.consumeContextual("from", "expect 'from' after imported names.")
+		path := p.consume(STRING, "expect module path.")
+		p.consume(SEMICOLON, "expect ';' after import.")
+		return NewImport(keyword, path, nil, names)
+	}
+
+	path := p.consume(STRING, "expect module path after 'import'.")
+	p.consumeContextual("as", "expect 'as' after module path.")
+	alias := p.consume(IDENTIFIER, "expect module name after 'as'.")
+	p.consume(SEMICOLON, "expect ';' after import.")
+	return NewImport(keyword, path, &alias, nil)
 }
 
 func (p *Parser) statement() Stmt {
@@ -120,6 +246,14 @@ func (p *Parser) statement() Stmt {
 		return p.returnStatement()
 	}
 
+	if p.match(THROW) {
+		return p.throwStatement()
+	}
+
+	if p.match(TRY) {
+		return p.tryStatement()
+	}
+
 	if p.match(WHILE) {
 		return p.whileStatement()
 	}
@@ -219,6 +353,40 @@ func (p *Parser) returnStatement() Stmt {
 	return NewReturn(keyword, value)
 }
 
+func (p *Parser) throwStatement() Stmt {
+	keyword := p.previous()
+	value := p.expression()
+	p.consume(SEMICOLON, "expect ';' after thrown value.")
+	return NewThrow(keyword, value)
+}
+
+func (p *Parser) tryStatement() Stmt {
+	keyword := p.previous()
+	p.consume(LEFT_BRACE, "expect '{' after 'try'.")
+	body := p.blockStatement()
+
+	var catchName *Token
+	var catchBody []Stmt
+	if p.match(CATCH) {
+		p.consume(LEFT_PAREN, "expect '(' after 'catch'.")
+		name := p.consume(IDENTIFIER, "expect exception variable name.")
+		catchName = &name
+		p.consume(RIGHT_PAREN, "expect ')' after exception variable name.")
+		p.consume(LEFT_BRACE, "expect '{' before catch body.")
+		catchBody = p.blockStatement()
+	}
+
+	var finallyBody []Stmt
+	if p.match(FINALLY) {
+		p.consume(LEFT_BRACE, "expect '{' after 'finally'.")
+		finallyBody = p.blockStatement()
+	} else if catchName == nil {
+		panic(NewLoxError(keyword, "expect 'catch' or 'finally' after try block."))
+	}
+
+	return NewTry(body, catchName, catchBody, finallyBody)
+}
+
 func (p *Parser) expressionStatement() Stmt {
 	expr := p.expression()
 	p.consume(SEMICOLON, "expect ';' after expression.")
@@ -230,7 +398,7 @@ func (p *Parser) expression() Expr {
 }
 
 func (p *Parser) assignment() Expr {
-	expr := p.or()
+	expr := p.conditional()
 
 	if p.match(EQUAL) {
 		equals := p.previous()
@@ -239,13 +407,68 @@ func (p *Parser) assignment() Expr {
 		if v, ok := expr.(*Variable); ok {
 			name := v.Name
 			return NewAssign(name, value)
-		} else if g, ok := expr.(*Get); ok {
+		} else if g, ok := expr.(*Get); ok && !g.Optional {
 			return NewSet(g.Object, g.Name, value)
 		}
 
 		p.reportError(equals, "invalid assignment target")
 	}
 
+	if p.match(PLUS_EQUAL, MINUS_EQUAL, STAR_EQUAL, SLASH_EQUAL, PERCENT_EQUAL) {
+		operator := p.previous()
+		value := p.assignment()
+		return p.compoundAssignment(expr, operator, value, false)
+	}
+
+	return expr
+}
+
+// compoundAssignment builds the update of target by operator, which is either
+// a compound assignment operator like += or an increment operator like ++.
+func (p *Parser) compoundAssignment(target Expr, operator Token, value Expr, postfix bool) Expr {
+	// Keep the lexeme for error messages but evaluate as the binary operator.
+	switch operator.TokenType {
+	case PLUS_EQUAL, PLUS_PLUS:
+		operator.TokenType = PLUS
+	case MINUS_EQUAL, MINUS_MINUS:
+		operator.TokenType = MINUS
+	case STAR_EQUAL:
+		operator.TokenType = STAR
+	case SLASH_EQUAL:
+		operator.TokenType = SLASH
+	case PERCENT_EQUAL:
+		operator.TokenType = PERCENT
+	}
+
+	if v, ok := target.(*Variable); ok {
+		return NewCompoundAssign(v.Name, operator, value, postfix)
+	} else if g, ok := target.(*Get); ok && !g.Optional {
+		return NewCompoundSet(g.Object, g.Name, operator, value, postfix)
+	}
+
+	p.reportError(operator, "invalid assignment target")
+	return target
+}
+
+func (p *Parser) conditional() Expr {
+	expr := p.nullCoalesce()
+
+	if p.match(QUESTION) {
+		thenBranch := p.expression()
+		p.consume(COLON, "expect ':' after then branch of conditional expression.")
+		elseBranch := p.conditional()
+		expr = NewConditional(expr, thenBranch, elseBranch)
+	}
+	return expr
+}
+
+func (p *Parser) nullCoalesce() Expr {
+	expr := p.or()
+	for p.match(QUESTION_QUESTION) {
+		operator := p.previous()
+		right := p.or()
+		expr = NewLogical(expr, operator, right)
+	}
 	return expr
 }
 
@@ -280,8 +503,48 @@ func (p *Parser) equality() Expr {
 }
 
 func (p *Parser) comparison() Expr {
-	expr := p.term()
+	expr := p.bitwiseOr()
 	for p.match(GREATER, GREATER_EQUAL, LESS, LESS_EQUAL) {
+		operator := p.previous()
+		right := p.bitwiseOr()
+		expr = NewBinary(expr, operator, right)
+	}
+	return expr
+}
+
+func (p *Parser) bitwiseOr() Expr {
+	expr := p.bitwiseXor()
+	for p.match(PIPE) {
+		operator := p.previous()
+		right := p.bitwiseXor()
+		expr = NewBinary(expr, operator, right)
+	}
+	return expr
+}
+
+func (p *Parser) bitwiseXor() Expr {
+	expr := p.bitwiseAnd()
+	for p.match(CARET) {
+		operator := p.previous()
+		right := p.bitwiseAnd()
+		expr = NewBinary(expr, operator, right)
+	}
+	return expr
+}
+
+func (p *Parser) bitwiseAnd() Expr {
+	expr := p.shift()
+	for p.match(AMPERSAND) {
+		operator := p.previous()
+		right := p.shift()
+		expr = NewBinary(expr, operator, right)
+	}
+	return expr
+}
+
+func (p *Parser) shift() Expr {
+	expr := p.term()
+	for p.match(LESS_LESS, GREATER_GREATER) {
 		operator := p.previous()
 		right := p.term()
 		expr = NewBinary(expr, operator, right)
@@ -301,7 +564,7 @@ func (p *Parser) term() Expr {
 
 func (p *Parser) factor() Expr {
 	expr := p.unary()
-	for p.match(SLASH, STAR) {
+	for p.match(SLASH, STAR, PERCENT, TILDE_SLASH) {
 		operator := p.previous()
 		right := p.unary()
 		expr = NewBinary(expr, operator, right)
@@ -310,21 +573,59 @@ func (p *Parser) factor() Expr {
 }
 
 func (p *Parser) unary() Expr {
-	if p.match(BANG, MINUS) {
+	if p.match(BANG, MINUS, TILDE) {
 		operator := p.previous()
 		right := p.unary()
 		return NewUnary(operator, right)
 	}
-	return p.call()
+	if p.match(PLUS_PLUS, MINUS_MINUS) {
+		operator := p.previous()
+		target := p.unary()
+		return p.compoundAssignment(target, operator, NewLiteral(1.0), false)
+	}
+	return p.power()
+}
+
+func (p *Parser) power() Expr {
+	expr := p.postfix()
+	if p.match(STAR_STAR) {
+		operator := p.previous()
+		// The exponent may be signed and ** is right-associative.
+		right := p.unary()
+		expr = NewBinary(expr, operator, right)
+	}
+	return expr
+}
+
+func (p *Parser) postfix() Expr {
+	expr := p.call()
+	if p.match(PLUS_PLUS, MINUS_MINUS) {
+		operator := p.previous()
+		return p.compoundAssignment(expr, operator, NewLiteral(1.0), true)
+	}
+	return expr
 }
 
 func (p *Parser) finishCall(callee Expr) Expr {
 	var arguments []Expr
+	var names []Token
 	if !p.check(RIGHT_PAREN) {
 		for {
 			if len(arguments) >= 255 {
 				p.reportError(p.peek(), "can't have more than 255 arguments")
 			}
+			if p.check(IDENTIFIER) && p.checkNext(COLON) {
+				name := p.advance()
+				p.advance()
+				for _, previous := range names {
+					if previous.Lexeme == name.Lexeme {
+						p.reportError(name, fmt.Sprintf("duplicate argument '%v'.", name.Lexeme))
+					}
+				}
+				names = append(names, name)
+			} else if len(names) > 0 {
+				p.reportError(p.peek(), "positional argument can't follow named arguments.")
+			}
 			arguments = append(arguments, p.expression())
 			if !p.match(COMMA) {
 				break
@@ -333,22 +634,30 @@ func (p *Parser) finishCall(callee Expr) Expr {
 	}
 
 	paren := p.consume(RIGHT_PAREN, "expect ')' after arguments.")
-	return NewCall(callee, paren, arguments)
+	return NewCall(callee, paren, arguments, names)
 }
 
 func (p *Parser) call() Expr {
 	expr := p.primary()
 
+	optional := false
 	for {
 		if p.match(LEFT_PAREN) {
 			expr = p.finishCall(expr)
 		} else if p.match(DOT) {
 			name := p.consume(IDENTIFIER, "expect property after '.'.")
-			expr = NewGet(name, expr)
+			expr = NewGet(name, expr, false)
+		} else if p.match(QUESTION_DOT) {
+			name := p.consume(IDENTIFIER, "expect property after '?.'.")
+			expr = NewGet(name, expr, true)
+			optional = true
 		} else {
 			break
 		}
 	}
+	if optional {
+		return NewOptionalChain(expr)
+	}
 	return expr
 }
 
@@ -366,6 +675,10 @@ func (p *Parser) primary() Expr {
 		return NewLiteral(p.previous().Literal)
 	}
 
+	if p.match(INTERPOLATION) {
+		return p.interpolation(p.previous())
+	}
+
 	if p.match(SUPER) {
 		keyword := p.previous()
 		p.consume(DOT, "expect '.' after 'super'.")
@@ -389,6 +702,36 @@ func (p *Parser) primary() Expr {
 	panic(NewLoxError(p.peek(), "expected expression"))
 }
 
+// interpolation desugars an interpolated string into a chain of string
+// concatenations where every embedded expression is passed through stringify.
+func (p *Parser) interpolation(token Token) Expr {
+	plus := Token{TokenType: PLUS, Lexeme: "+", Line: token.Line, Column: token.Column}
+
+	var expr Expr
+	for _, part := range token.Literal.([]interface{}) {
+		var operand Expr
+		switch v := part.(type) {
+		case string:
+			operand = NewLiteral(v)
+		case []Token:
+			nested := NewParser(v)
+			value := nested.expression()
+			nested.consume(RIGHT_BRACE, "expect '}' after interpolated expression.")
+			if nested.hadError {
+				p.hadError = true
+			}
+			operand = NewCall(NewLiteral(stringifyCallable), token, []Expr{value}, nil)
+		}
+
+		if expr == nil {
+			expr = operand
+		} else {
+			expr = NewBinary(expr, plus, operand)
+		}
+	}
+	return expr
+}
+
 func (p *Parser) match(tokenTypes ...TokenType) bool {
 	for _, t := range tokenTypes {
 		if p.check(t) {
@@ -406,6 +749,13 @@ func (p *Parser) check(tokenType TokenType) bool {
 	return p.peek().TokenType == tokenType
 }
 
+func (p *Parser) checkNext(tokenType TokenType) bool {
+	if p.isAtEnd() || p.tokens[p.current+1].TokenType == EOF {
+		return false
+	}
+	return p.tokens[p.current+1].TokenType == tokenType
+}
+
 func (p *Parser) advance() Token {
 	if !p.isAtEnd() {
 		p.current++
@@ -432,6 +782,26 @@ func (p *Parser) consume(tokenType TokenType, message string) Token {
 	panic(NewLoxError(p.peek(), message))
 }
 
+// consumeContextual consumes an identifier that acts as a keyword only in
+// this position, like 'as' and 'from' in imports.
+func (p *Parser) consumeContextual(keyword string, message string) Token {
+	if p.check(IDENTIFIER) && p.peek().Lexeme == keyword {
+		return p.advance()
+	}
+
+	panic(NewLoxError(p.peek(), message))
+}
+
+// matchContextual consumes an identifier used as the keyword modifier, which
+// must be followed by the name it modifies, so it can still name a method.
+func (p *Parser) matchContextual(keyword string) bool {
+	if p.check(IDENTIFIER) && p.peek().Lexeme == keyword && p.checkNext(IDENTIFIER) {
+		p.advance()
+		return true
+	}
+	return false
+}
+
 func (p *Parser) synchronize() {
 	p.advance()
 
@@ -441,14 +811,7 @@ func (p *Parser) synchronize() {
 		}
 
 		switch p.peek().TokenType {
-		case CLASS:
-		case FUN:
-		case VAR:
-		case FOR:
-		case IF:
-		case WHILE:
-		case PRINT:
-		case RETURN:
+		case CLASS, TRAIT, FUN, VAR, CONST, FOR, IF, WHILE, PRINT, RETURN, THROW, TRY, IMPORT:
 			return
 		}
 
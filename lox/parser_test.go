@@ -0,0 +1,18 @@
+package lox
+
+import "testing"
+
+func TestInterpolationErrors(t *testing.T) {
+	for _, source := range []string{
+		`print "x${a = 1 = 2}y";`,
+		`print "${f(a: 1, 2)}";`,
+	} {
+		tokens, err := NewScanner(source).ScanTokens()
+		if err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		if _, err := NewParser(tokens).Parse(); err == nil {
+			t.Errorf("%v: expected a parse error", source)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package lox
+
+import (
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+)
+
+// LoxRegex is a compiled regular expression.
+type LoxRegex struct {
+	Regexp *regexp.Regexp
+}
+
+func NewLoxRegex(re *regexp.Regexp) *LoxRegex {
+	return &LoxRegex{
+		Regexp: re,
+	}
+}
+
+func (r LoxRegex) String() string {
+	return fmt.Sprintf("<regex %v>", r.Regexp.String())
+}
+
+func (r *LoxRegex) Get(name Token) interface{} {
+	if name.Lexeme == "pattern" {
+		return r.Regexp.String()
+	}
+	if method, ok := regexMethods[name.Lexeme]; ok {
+		return NewProtoCallable(method.arity, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return method.call(interpreter, r.Regexp, arguments)
+		})
+	}
+	panic(NewRuntimeError(name, fmt.Sprintf("undefined property %v .", name.Lexeme)))
+}
+
+type regexMethod struct {
+	arity int
+	call  func(interpreter *Interpreter, re *regexp.Regexp, arguments []interface{}) interface{}
+}
+
+// regexMethods are the methods of compiled patterns. The re module offers
+// each of them as a function taking the pattern as an extra first argument.
+var regexMethods = map[string]regexMethod{
+	"match": {1, func(interpreter *Interpreter, re *regexp.Regexp, arguments []interface{}) interface{} {
+		return re.MatchString(interpreter.stringArgument("match", arguments, 0))
+	}},
+	"find": {1, func(interpreter *Interpreter, re *regexp.Regexp, arguments []interface{}) interface{} {
+		s := interpreter.stringArgument("find", arguments, 0)
+		indices := re.FindStringSubmatchIndex(s)
+		if indices == nil {
+			return nil
+		}
+		return matchResult(re, s, indices)
+	}},
+	"findAll": {1, func(interpreter *Interpreter, re *regexp.Regexp, arguments []interface{}) interface{} {
+		s := interpreter.stringArgument("findAll", arguments, 0)
+		elements := []interface{}{}
+		for _, indices := range re.FindAllStringSubmatchIndex(s, -1) {
+			elements = append(elements, matchResult(re, s, indices))
+		}
+		return NewLoxList(elements)
+	}},
+	// replace expands $1 and ${name} in the replacement to captured groups.
+	"replace": {2, func(interpreter *Interpreter, re *regexp.Regexp, arguments []interface{}) interface{} {
+		s := interpreter.stringArgument("replace", arguments, 0)
+		return re.ReplaceAllString(s, interpreter.stringArgument("replace", arguments, 1))
+	}},
+	"split": {1, func(interpreter *Interpreter, re *regexp.Regexp, arguments []interface{}) interface{} {
+		elements := []interface{}{}
+		for _, part := range re.Split(interpreter.stringArgument("split", arguments, 0), -1) {
+			elements = append(elements, part)
+		}
+		return NewLoxList(elements)
+	}},
+}
+
+// matchResult describes a match as a map with the matched text, its start
+// and end in characters, the list of groups and the map of named groups.
+// Groups that did not participate in the match are nil.
+func matchResult(re *regexp.Regexp, s string, indices []int) *LoxMap {
+	groups := []interface{}{}
+	named := NewLoxMap()
+	for i, name := range re.SubexpNames() {
+		if i == 0 {
+			continue
+		}
+		var group interface{}
+		if indices[2*i] >= 0 {
+			group = s[indices[2*i]:indices[2*i+1]]
+		}
+		groups = append(groups, group)
+		if name != "" {
+			named.Put(name, group)
+		}
+	}
+
+	result := NewLoxMap()
+	result.Put("text", s[indices[0]:indices[1]])
+	result.Put("start", float64(utf8.RuneCountInString(s[:indices[0]])))
+	result.Put("end", float64(utf8.RuneCountInString(s[:indices[1]])))
+	result.Put("groups", NewLoxList(groups))
+	result.Put("named", named)
+	return result
+}
+
+func newReModule() *LoxModule {
+	values := map[string]interface{}{}
+
+	values["compile"] = NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		return NewLoxRegex(interpreter.regexArgument("compile", arguments))
+	})
+
+	for name, method := range regexMethods {
+		name, method := name, method
+		values[name] = NewProtoCallable(method.arity+1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return method.call(interpreter, interpreter.regexArgument(name, arguments), arguments[1:])
+		})
+	}
+
+	return NewLoxModule("re", "", values)
+}
+
+// regexArgument returns the first argument as a compiled pattern, compiling
+// it if it is a string.
+func (i *Interpreter) regexArgument(name string, arguments []interface{}) *regexp.Regexp {
+	if r, ok := arguments[0].(*LoxRegex); ok {
+		return r.Regexp
+	}
+	re, err := regexp.Compile(i.stringArgument(name, arguments, 0))
+	if err != nil {
+		i.nativeError("%v: %v.", name, err)
+	}
+	return re
+}
@@ -0,0 +1,163 @@
+package lox
+
+import "sort"
+
+func defineReflectionNatives(env *Environment) {
+	env.Define("type", NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		return typeName(arguments[0])
+	}))
+
+	env.Define("isInstance", NewProtoCallable(2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		class := interpreter.classArgument("isInstance", arguments, 1)
+		instance, ok := arguments[0].(*LoxInstance)
+		if !ok {
+			return false
+		}
+		for c := instance.Class; c != nil; c = c.Superclass {
+			if c == class {
+				return true
+			}
+		}
+		return false
+	}).WithParams("object", "class"))
+
+	env.Define("classOf", NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		return interpreter.instanceArgument("classOf", arguments, 0).Class
+	}))
+
+	env.Define("fields", NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		return sortedNames(interpreter.fieldsArgument("fields", arguments, 0))
+	}))
+
+	env.Define("methods", NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		class, ok := arguments[0].(*LoxClass)
+		if instance, isInstance := arguments[0].(*LoxInstance); isInstance {
+			class, ok = instance.Class, true
+		}
+		if !ok {
+			interpreter.nativeError("methods: argument 1 must be a class or an instance.")
+		}
+
+		methods := map[string]interface{}{}
+		for c := class; c != nil; c = c.Superclass {
+			for name := range c.Methods {
+				methods[name] = nil
+			}
+		}
+		return sortedNames(methods)
+	}))
+
+	env.Define("hasField", NewProtoCallable(2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		fields := interpreter.fieldsArgument("hasField", arguments, 0)
+		_, ok := fields[interpreter.stringArgument("hasField", arguments, 1)]
+		return ok
+	}).WithParams("object", "name"))
+
+	env.Define("getField", NewProtoCallable(2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		fields := interpreter.fieldsArgument("getField", arguments, 0)
+		name := interpreter.stringArgument("getField", arguments, 1)
+		value, ok := fields[name]
+		if !ok {
+			interpreter.nativeError("getField: %v has no field '%v'.", stringify(arguments[0]), name)
+		}
+		return value
+	}).WithParams("object", "name"))
+
+	env.Define("setField", NewProtoCallable(3, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		fields := interpreter.fieldsArgument("setField", arguments, 0)
+		name := interpreter.stringArgument("setField", arguments, 1)
+		if instance, ok := arguments[0].(*LoxInstance); ok && instance.initialized && instance.Class.IsFinal(name) {
+			interpreter.nativeError("setField: can't assign to final field '%v' outside of init.", name)
+		}
+		fields[name] = arguments[2]
+		return arguments[2]
+	}).WithParams("object", "name", "value"))
+
+	// arity returns the number of arguments a callable requires.
+	env.Define("arity", NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		f, ok := arguments[0].(Callable)
+		if !ok {
+			interpreter.nativeError("arity: argument 1 must be callable.")
+		}
+		return float64(f.MinArity())
+	}))
+}
+
+// typeName returns the name type() reports for value: the class name for
+// instances, otherwise the kind of value.
+func typeName(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case *LoxInstance:
+		return v.Class.Name
+	case *LoxClass:
+		return "class"
+	case *LoxTrait:
+		return "trait"
+	case *LoxList:
+		return "list"
+	case *LoxMap:
+		return "map"
+	case *LoxModule:
+		return "module"
+	case *LoxException:
+		return "error"
+	case *LoxDate:
+		return "date"
+	case *LoxRegex:
+		return "regex"
+	case Callable:
+		return "function"
+	}
+	return "unknown"
+}
+
+func (i *Interpreter) instanceArgument(name string, arguments []interface{}, index int) *LoxInstance {
+	v, ok := arguments[index].(*LoxInstance)
+	if !ok {
+		i.nativeError("%v: argument %v must be an instance.", name, index+1)
+	}
+	return v
+}
+
+func (i *Interpreter) classArgument(name string, arguments []interface{}, index int) *LoxClass {
+	v, ok := arguments[index].(*LoxClass)
+	if !ok {
+		i.nativeError("%v: argument %v must be a class.", name, index+1)
+	}
+	return v
+}
+
+// fieldsArgument returns the fields of an instance, or the static fields of
+// a class.
+func (i *Interpreter) fieldsArgument(name string, arguments []interface{}, index int) map[string]interface{} {
+	switch v := arguments[index].(type) {
+	case *LoxInstance:
+		return v.Fields
+	case *LoxClass:
+		return v.Fields
+	}
+	i.nativeError("%v: argument %v must be an instance or a class.", name, index+1)
+	return nil
+}
+
+func sortedNames(values map[string]interface{}) *LoxList {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	elements := make([]interface{}, len(names))
+	for i, name := range names {
+		elements[i] = name
+	}
+	return NewLoxList(elements)
+}
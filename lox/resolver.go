@@ -1,13 +1,17 @@
 package lox
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"os"
 
 	lls "github.com/emirpasic/gods/stacks/linkedliststack"
 )
 
 type FunctionType int
 type ClassType int
+type variableKind int
 
 const (
 	NONE FunctionType = iota
@@ -20,14 +24,49 @@ const (
 	CLASS_NONE ClassType = iota
 	CLASS_CLASS
 	CLASS_SUBCLASS
+	CLASS_TRAIT
 )
 
+const (
+	VARIABLE_LOCAL variableKind = iota
+	VARIABLE_PARAMETER
+	VARIABLE_IMPLICIT
+)
+
+// variable is what the resolver knows about a name declared in a local
+// scope. function is its declaration when the name was declared with 'fun'.
+type variable struct {
+	name     Token
+	kind     variableKind
+	defined  bool
+	constant bool
+	used     bool
+	function *Function
+}
+
+// globalCall is a call to a global, checked against the global's function
+// declaration once the whole program has been resolved.
+type globalCall struct {
+	name      Token
+	arguments int
+	named     bool
+}
+
 type Resolver struct {
 	interpreter     *Interpreter
 	scopes          lls.Stack
 	currentFunction FunctionType
 	currentClass    ClassType
+	inClassMethod   bool
 	hadRuntimeError bool
+
+	globals           map[string]bool
+	globalFunctions   map[string]*Function
+	globalAssignments []Token
+	globalCalls       []globalCall
+	ignored           map[int]IgnoreComment
+	warnings          []*Warning
+	warningOutput     io.Writer
 }
 
 func NewResolver(interpreter *Interpreter) *Resolver {
@@ -37,18 +76,27 @@ func NewResolver(interpreter *Interpreter) *Resolver {
 		currentFunction: NONE,
 		currentClass:    CLASS_NONE,
 		hadRuntimeError: false,
+		globals:         map[string]bool{},
+		globalFunctions: map[string]*Function{},
+		warningOutput:   os.Stderr,
 	}
 }
 
+// SetWarningOutput sets where warnings are printed, standard error by
+// default.
+func (r *Resolver) SetWarningOutput(w io.Writer) {
+	r.warningOutput = w
+}
+
 func (r *Resolver) VisitBlockStmt(stmt Block) {
 	r.beginScope()
-	r.ResolveStatements(stmt.Statements)
+	r.resolveStatements(stmt.Statements)
 	r.endScope()
 }
 
 func (r *Resolver) VisitClassStmt(stmt Class) {
-	enclosingClass := r.currentClass
-	r.currentClass = CLASS_CLASS
+	enclosingClass, enclosingClassMethod := r.currentClass, r.inClassMethod
+	r.currentClass, r.inClassMethod = CLASS_CLASS, false
 
 	r.declare(stmt.Name)
 	r.define(stmt.Name)
@@ -62,15 +110,17 @@ func (r *Resolver) VisitClassStmt(stmt Class) {
 		r.resolveExpression(stmt.Superclass)
 	}
 
+	for _, trait := range stmt.Traits {
+		r.resolveExpression(trait)
+	}
+
 	if stmt.Superclass != nil {
 		r.beginScope()
-		scope, _ := r.scopes.Peek()
-		scope.(map[string]bool)["super"] = true
+		r.defineImplicit("super")
 	}
 
 	r.beginScope()
-	scope, _ := r.scopes.Peek()
-	scope.(map[string]bool)["this"] = true
+	r.defineImplicit("this")
 
 	for _, method := range stmt.Methods {
 		declaration := METHOD
@@ -82,12 +132,55 @@ func (r *Resolver) VisitClassStmt(stmt Class) {
 		r.resolveFunction(method, declaration)
 	}
 
+	for _, method := range stmt.Getters {
+		r.resolveFunction(method, METHOD)
+	}
+
+	for _, method := range stmt.Setters {
+		r.resolveFunction(method, METHOD)
+	}
+
+	r.inClassMethod = true
+	for _, method := range stmt.ClassMethods {
+		r.resolveFunction(method, METHOD)
+	}
+
 	if stmt.Superclass != nil {
 		r.endScope()
 	}
 
-	r.currentClass = enclosingClass
+	r.currentClass, r.inClassMethod = enclosingClass, enclosingClassMethod
+	r.endScope()
+}
+
+// VisitTraitStmt resolves trait methods inside the same 'super' and 'this'
+// scopes as class methods, since they are bound into the including class.
+func (r *Resolver) VisitTraitStmt(stmt Trait) {
+	enclosingClass, enclosingClassMethod := r.currentClass, r.inClassMethod
+	r.currentClass, r.inClassMethod = CLASS_TRAIT, false
+
+	r.declare(stmt.Name)
+	r.define(stmt.Name)
+
+	r.beginScope()
+	r.defineImplicit("super")
+
+	r.beginScope()
+	r.defineImplicit("this")
+
+	for _, method := range stmt.Methods {
+		declaration := METHOD
+
+		if method.Name.Lexeme == "init" {
+			declaration = INITIALIZER
+		}
+
+		r.resolveFunction(method, declaration)
+	}
+
+	r.endScope()
 	r.endScope()
+	r.currentClass, r.inClassMethod = enclosingClass, enclosingClassMethod
 }
 
 func (r *Resolver) VisitExpressionStmt(stmt Expression) {
@@ -100,11 +193,21 @@ func (r *Resolver) VisitVarStmt(stmt Var) {
 		r.resolveExpression(stmt.Initializer)
 	}
 	r.define(stmt.Name)
+	if stmt.Constant && !r.scopes.Empty() {
+		scope, _ := r.scopes.Peek()
+		(scope.(map[string]*variable))[stmt.Name.Lexeme].constant = true
+	}
 }
 
 func (r *Resolver) VisitFunctionStmt(stmt Function) {
 	r.declare(stmt.Name)
 	r.define(stmt.Name)
+	if r.scopes.Empty() {
+		r.globalFunctions[stmt.Name.Lexeme] = &stmt
+	} else {
+		scope, _ := r.scopes.Peek()
+		(scope.(map[string]*variable))[stmt.Name.Lexeme].function = &stmt
+	}
 	r.resolveFunction(stmt, FUNCTION)
 }
 
@@ -133,14 +236,63 @@ func (r *Resolver) VisitReturnStmt(stmt Return) {
 	}
 }
 
+func (r *Resolver) VisitThrowStmt(stmt Throw) {
+	r.resolveExpression(stmt.Value)
+}
+
+func (r *Resolver) VisitTryStmt(stmt Try) {
+	r.beginScope()
+	r.resolveStatements(stmt.Body)
+	r.endScope()
+
+	if stmt.CatchName != nil {
+		r.beginScope()
+		r.declare(*stmt.CatchName)
+		r.define(*stmt.CatchName)
+		r.resolveStatements(stmt.CatchBody)
+		r.endScope()
+	}
+
+	if stmt.FinallyBody != nil {
+		r.beginScope()
+		r.resolveStatements(stmt.FinallyBody)
+		r.endScope()
+	}
+}
+
+func (r *Resolver) VisitImportStmt(stmt Import) {
+	if stmt.Alias != nil {
+		r.declare(*stmt.Alias)
+		r.define(*stmt.Alias)
+	}
+	for _, name := range stmt.Names {
+		r.declare(name)
+		r.define(name)
+	}
+}
+
 func (r *Resolver) VisitWhileStmt(stmt While) {
 	r.resolveExpression(stmt.Condition)
 	r.resolveStatement(stmt.Body)
 }
 
-func (r *Resolver) VisitAssignExpr(expr Assign) interface{} {
+func (r *Resolver) VisitAssignExpr(expr *Assign) interface{} {
+	r.resolveExpression(expr.Value)
+	r.resolveAssignment(expr, expr.Name)
+	return nil
+}
+
+func (r *Resolver) VisitCompoundAssignExpr(expr *CompoundAssign) interface{} {
+	r.resolveExpression(expr.Value)
+	if v := r.resolveAssignment(expr, expr.Name); v != nil {
+		v.used = true
+	}
+	return nil
+}
+
+func (r *Resolver) VisitCompoundSetExpr(expr CompoundSet) interface{} {
 	r.resolveExpression(expr.Value)
-	r.resolveLocal(&expr, expr.Name)
+	r.resolveExpression(expr.Object)
 	return nil
 }
 
@@ -155,6 +307,22 @@ func (r *Resolver) VisitCallExpr(expr Call) interface{} {
 	for _, arg := range expr.Arguments {
 		r.resolveExpression(arg)
 	}
+
+	if callee, ok := expr.Callee.(*Variable); ok {
+		named := len(expr.Names) > 0
+		if v, _ := r.lookUp(callee.Name); v == nil {
+			r.globalCalls = append(r.globalCalls, globalCall{name: callee.Name, arguments: len(expr.Arguments), named: named})
+		} else if v.function != nil {
+			r.checkCall(v.function, callee.Name, len(expr.Arguments), named)
+		}
+	}
+	return nil
+}
+
+func (r *Resolver) VisitConditionalExpr(expr Conditional) interface{} {
+	r.resolveExpression(expr.Condition)
+	r.resolveExpression(expr.ThenBranch)
+	r.resolveExpression(expr.ElseBranch)
 	return nil
 }
 
@@ -163,6 +331,11 @@ func (r *Resolver) VisitGetExpr(expr Get) interface{} {
 	return nil
 }
 
+func (r *Resolver) VisitOptionalChainExpr(expr OptionalChain) interface{} {
+	r.resolveExpression(expr.Expression)
+	return nil
+}
+
 func (r *Resolver) VisitGroupingExpr(expr Grouping) interface{} {
 	r.resolveExpression(expr.Expression)
 	return nil
@@ -187,7 +360,7 @@ func (r *Resolver) VisitSetExpr(expr Set) interface{} {
 func (r *Resolver) VisitSuperExpr(expr *Super) interface{} {
 	if r.currentClass == CLASS_NONE {
 		panic(NewLoxError(expr.Keyword, "can't use 'super' outside of a class"))
-	} else if r.currentClass != CLASS_SUBCLASS {
+	} else if r.currentClass != CLASS_SUBCLASS && r.currentClass != CLASS_TRAIT {
 		panic(NewLoxError(expr.Keyword, "can't use 'super' in a class with no superclass"))
 	}
 	r.resolveLocal(expr, expr.Keyword)
@@ -199,40 +372,61 @@ func (r *Resolver) VisitUnaryExpr(expr Unary) interface{} {
 	return nil
 }
 
-func (r *Resolver) VisitVariableExpr(expr Variable) interface{} {
+func (r *Resolver) VisitVariableExpr(expr *Variable) interface{} {
 	if !r.scopes.Empty() {
 		scope, _ := r.scopes.Peek()
-		if !(scope.(map[string]bool))[expr.Name.Lexeme] {
+		if v, ok := (scope.(map[string]*variable))[expr.Name.Lexeme]; ok && !v.defined {
 			panic(NewLoxError(expr.Name, "can't read local variabl in its own initializer."))
 		}
 	}
 
-	r.resolveLocal(&expr, expr.Name)
+	if v := r.resolveLocal(expr, expr.Name); v != nil {
+		v.used = true
+	}
 	return nil
 }
 
-func (r *Resolver) VisitThisExpr(expr This) interface{} {
+func (r *Resolver) VisitThisExpr(expr *This) interface{} {
 	if r.currentClass == CLASS_NONE {
 		panic(NewLoxError(expr.Keyword, "can't use 'this' outside of a class"))
 	}
-	r.resolveLocal(&expr, expr.Keyword)
+	if r.inClassMethod {
+		r.warn(WARN_THIS_IN_CLASS_METHOD, expr.Keyword, "'this' in a class method refers to the class, not an instance.")
+	}
+	r.resolveLocal(expr, expr.Keyword)
+	return nil
+}
+
+// ResolveStatements resolves a whole program and prints its warnings.
+func (r *Resolver) ResolveStatements(statements []Stmt) error {
+	r.resolveStatements(statements)
+	r.reportWarnings()
+
+	if r.hadRuntimeError {
+		return errors.New("error in resolver")
+	}
 	return nil
 }
 
-func (r *Resolver) ResolveStatements(statements []Stmt) (err error) {
+// resolveStatements reports an error and skips the rest of statements, so
+// that an error in a nested block or function doesn't stop the resolution of
+// the statements around it.
+func (r *Resolver) resolveStatements(statements []Stmt) {
 	defer func() {
 		if val := recover(); val != nil {
-			loxError := val.(*LoxError)
+			loxError, ok := val.(*LoxError)
+			if !ok {
+				panic(val)
+			}
 			fmt.Println(loxError.Error())
-			err = loxError
 			r.hadRuntimeError = true
 		}
 	}()
 
+	r.checkUnreachable(statements)
 	for _, s := range statements {
 		r.resolveStatement(s)
 	}
-	return nil
 }
 
 func (r *Resolver) resolveStatement(stmt Stmt) {
@@ -244,25 +438,41 @@ func (r *Resolver) resolveExpression(expr Expr) {
 }
 
 func (r *Resolver) beginScope() {
-	r.scopes.Push(make(map[string]bool))
+	r.scopes.Push(make(map[string]*variable))
 }
 
 func (r *Resolver) endScope() {
-	r.scopes.Pop()
+	scope, _ := r.scopes.Pop()
+	r.checkUnused(scope.(map[string]*variable))
 }
 
 func (r *Resolver) declare(name Token) {
 	if r.scopes.Empty() {
+		r.globals[name.Lexeme] = true
+		delete(r.globalFunctions, name.Lexeme)
 		return
 	}
 
 	scope, _ := r.scopes.Peek()
 
-	if _, ok := scope.(map[string]bool)[name.Lexeme]; ok {
+	if _, ok := scope.(map[string]*variable)[name.Lexeme]; ok {
 		panic(NewLoxError(name, "already a variable with this name in this scope."))
 	}
 
-	(scope.(map[string]bool))[name.Lexeme] = false
+	if v, _ := r.lookUp(name); v != nil && v.kind != VARIABLE_IMPLICIT {
+		r.warn(WARN_SHADOWED_VARIABLE, name, fmt.Sprintf("'%v' shadows a variable declared on line %v.", name.Lexeme, v.name.Line))
+	} else if v == nil && r.globals[name.Lexeme] {
+		r.warn(WARN_SHADOWED_VARIABLE, name, fmt.Sprintf("'%v' shadows a global variable.", name.Lexeme))
+	}
+
+	(scope.(map[string]*variable))[name.Lexeme] = &variable{name: name}
+}
+
+func (r *Resolver) declareParameter(name Token) {
+	r.declare(name)
+	r.define(name)
+	scope, _ := r.scopes.Peek()
+	(scope.(map[string]*variable))[name.Lexeme].kind = VARIABLE_PARAMETER
 }
 
 func (r *Resolver) define(name Token) {
@@ -270,18 +480,54 @@ func (r *Resolver) define(name Token) {
 		return
 	}
 	scope, _ := r.scopes.Peek()
-	(scope.(map[string]bool))[name.Lexeme] = true
+	(scope.(map[string]*variable))[name.Lexeme].defined = true
+}
+
+// defineImplicit defines a name like 'this' that the interpreter binds
+// without a declaration.
+func (r *Resolver) defineImplicit(name string) {
+	scope, _ := r.scopes.Peek()
+	(scope.(map[string]*variable))[name] = &variable{kind: VARIABLE_IMPLICIT, defined: true}
 }
 
-func (r *Resolver) resolveLocal(expr Expr, name Token) {
+// lookUp returns the local variable name refers to and how many scopes out
+// it is declared, or nil if it isn't a local.
+func (r *Resolver) lookUp(name Token) (*variable, int) {
 	iter := r.scopes.Iterator()
 	scopeDeep := 0
 	for iter.Next() {
-		if _, ok := (iter.Value().(map[string]bool))[name.Lexeme]; ok {
-			r.interpreter.Resolve(expr, scopeDeep)
+		if v, ok := (iter.Value().(map[string]*variable))[name.Lexeme]; ok {
+			return v, scopeDeep
 		}
 		scopeDeep++
 	}
+	return nil, 0
+}
+
+// resolveLocal returns the local variable expr refers to, or nil if it is a
+// global.
+func (r *Resolver) resolveLocal(expr Expr, name Token) *variable {
+	v, distance := r.lookUp(name)
+	if v != nil {
+		r.interpreter.Resolve(expr, distance)
+	}
+	return v
+}
+
+// resolveAssignment resolves an assignment to name, which must not be a
+// local constant. Constant globals are checked when the assignment runs.
+func (r *Resolver) resolveAssignment(expr Expr, name Token) *variable {
+	v, _ := r.lookUp(name)
+	if v != nil && v.constant {
+		panic(NewLoxError(name, fmt.Sprintf("can't assign to constant '%v'.", name.Lexeme)))
+	}
+	if v == nil {
+		r.globalAssignments = append(r.globalAssignments, name)
+	} else {
+		// The variable may no longer hold the function it was declared as.
+		v.function = nil
+	}
+	return r.resolveLocal(expr, name)
 }
 
 func (r *Resolver) resolveFunction(function Function, typeF FunctionType) {
@@ -289,11 +535,16 @@ func (r *Resolver) resolveFunction(function Function, typeF FunctionType) {
 	r.currentFunction = typeF
 
 	r.beginScope()
-	for _, param := range function.Params {
-		r.declare(param)
-		r.define(param)
+	for i, param := range function.Params {
+		if function.Defaults[i] != nil {
+			r.resolveExpression(function.Defaults[i])
+		}
+		r.declareParameter(param)
+	}
+	if function.Rest != nil {
+		r.declareParameter(*function.Rest)
 	}
-	r.ResolveStatements(function.Body)
+	r.resolveStatements(function.Body)
 	r.endScope()
 
 	r.currentFunction = enclosingFunction
@@ -0,0 +1,39 @@
+package lox
+
+// Capability is a set of privileged operations natives may perform. Embedding
+// hosts restrict scripts by denying capabilities.
+type Capability int
+
+const (
+	// CAP_FILESYSTEM allows reading and writing files.
+	CAP_FILESYSTEM Capability = 1 << iota
+	// CAP_ENV allows reading and changing environment variables and reading
+	// the working directory.
+	CAP_ENV
+	// CAP_EXIT allows scripts to end the program with os.exit.
+	CAP_EXIT
+	// CAP_EXEC allows running external commands.
+	CAP_EXEC
+)
+
+// DEFAULT_CAPABILITIES are the capabilities of a new interpreter. Access to
+// the process environment and to other processes must be allowed explicitly.
+const DEFAULT_CAPABILITIES = CAP_FILESYSTEM
+
+// Allow grants the capabilities in c.
+func (i *Interpreter) Allow(c Capability) {
+	i.capabilities |= c
+}
+
+// Deny revokes the capabilities in c.
+func (i *Interpreter) Deny(c Capability) {
+	i.capabilities &^= c
+}
+
+// requireCapability reports a runtime error in the native name unless c
+// is allowed.
+func (i *Interpreter) requireCapability(c Capability, name string, description string) {
+	if i.capabilities&c != c {
+		i.nativeError("%v: %v is disabled.", name, description)
+	}
+}
@@ -4,17 +4,23 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 type Scanner struct {
 	source string
 	tokens []Token
 
-	start   int
-	current int
-	line    int
+	start       int
+	current     int
+	line        int
+	column      int
+	startColumn int
 
 	keywords map[string]TokenType
+	ignored  map[int]IgnoreComment
 
 	hadError bool
 }
@@ -25,13 +31,21 @@ func NewScanner(source string) *Scanner {
 		tokens:   []Token{},
 		start:    0,
 		current:  0,
-		line:     0,
-		keywords: map[string]TokenType{"and": AND, "class": CLASS, "else": ELSE, "false": FALSE, "for": FOR, "fun": FUN, "if": IF, "nil": NIL, "or": OR, "print": PRINT, "return": RETURN, "super": SUPER, "this": THIS, "true": TRUE, "var": VAR, "while": WHILE},
+		line:     1,
+		column:   0,
+		ignored:  map[int]IgnoreComment{},
+		keywords: map[string]TokenType{"and": AND, "catch": CATCH, "class": CLASS, "const": CONST, "else": ELSE, "false": FALSE, "finally": FINALLY, "for": FOR, "fun": FUN, "if": IF, "import": IMPORT, "nil": NIL, "or": OR, "print": PRINT, "return": RETURN, "super": SUPER, "this": THIS, "throw": THROW, "trait": TRAIT, "true": TRUE, "try": TRY, "var": VAR, "while": WHILE},
 	}
 }
 
-func (s *Scanner) reportError(line int, where string, error string) {
-	fmt.Printf("[line %v] Error%v: %v", strconv.Itoa(line), where, error)
+// IgnoredWarnings returns the '// lox:ignore' comments by the line of the
+// comment.
+func (s *Scanner) IgnoredWarnings() map[int]IgnoreComment {
+	return s.ignored
+}
+
+func (s *Scanner) reportError(line int, column int, error string) {
+	fmt.Printf("[line %v, column %v] Error: %v\n", line, column, error)
 	s.hadError = true
 }
 
@@ -39,9 +53,10 @@ func (s *Scanner) ScanTokens() ([]Token, error) {
 	for !s.isAtEnd() {
 		// We are at the beginning of the next lexeme.
 		s.start = s.current
+		s.startColumn = s.column + 1
 		s.scanToken()
 	}
-	s.tokens = append(s.tokens, *NewToken(EOF, "", "", s.line))
+	s.tokens = append(s.tokens, *NewToken(EOF, "", "", s.line, s.column+1))
 	if s.hadError {
 		return nil, errors.New("error in scanner")
 	}
@@ -66,15 +81,67 @@ func (s *Scanner) scanToken() {
 	case ',':
 		s.addToken(COMMA)
 	case '.':
-		s.addToken(DOT)
+		if s.peek() == '.' && s.peekNext() == '.' {
+			s.advance()
+			s.advance()
+			s.addToken(DOT_DOT_DOT)
+		} else {
+			s.addToken(DOT)
+		}
 	case '-':
-		s.addToken(MINUS)
+		if s.match('-') {
+			s.addToken(MINUS_MINUS)
+		} else if s.match('=') {
+			s.addToken(MINUS_EQUAL)
+		} else {
+			s.addToken(MINUS)
+		}
 	case '+':
-		s.addToken(PLUS)
+		if s.match('+') {
+			s.addToken(PLUS_PLUS)
+		} else if s.match('=') {
+			s.addToken(PLUS_EQUAL)
+		} else {
+			s.addToken(PLUS)
+		}
 	case ';':
 		s.addToken(SEMICOLON)
 	case '*':
-		s.addToken(STAR)
+		if s.match('*') {
+			s.addToken(STAR_STAR)
+		} else if s.match('=') {
+			s.addToken(STAR_EQUAL)
+		} else {
+			s.addToken(STAR)
+		}
+	case '%':
+		if s.match('=') {
+			s.addToken(PERCENT_EQUAL)
+		} else {
+			s.addToken(PERCENT)
+		}
+	case '&':
+		s.addToken(AMPERSAND)
+	case '|':
+		s.addToken(PIPE)
+	case '^':
+		s.addToken(CARET)
+	case ':':
+		s.addToken(COLON)
+	case '?':
+		if s.match('?') {
+			s.addToken(QUESTION_QUESTION)
+		} else if s.match('.') {
+			s.addToken(QUESTION_DOT)
+		} else {
+			s.addToken(QUESTION)
+		}
+	case '~':
+		if s.match('/') {
+			s.addToken(TILDE_SLASH)
+		} else {
+			s.addToken(TILDE)
+		}
 	case '!':
 		if s.match('=') {
 			s.addToken(BANG_EQUAL)
@@ -90,12 +157,16 @@ func (s *Scanner) scanToken() {
 	case '<':
 		if s.match('=') {
 			s.addToken(LESS_EQUAL)
+		} else if s.match('<') {
+			s.addToken(LESS_LESS)
 		} else {
 			s.addToken(LESS)
 		}
 	case '>':
 		if s.match('=') {
 			s.addToken(GREATER_EQUAL)
+		} else if s.match('>') {
+			s.addToken(GREATER_GREATER)
 		} else {
 			s.addToken(GREATER)
 		}
@@ -105,17 +176,21 @@ func (s *Scanner) scanToken() {
 			for s.peek() != '\n' && !s.isAtEnd() {
 				s.advance()
 			}
+			if codes, ok := ignoreDirective(s.source[s.start:s.current]); ok {
+				s.ignored[s.line] = IgnoreComment{Codes: codes, Standalone: !s.tokenOnLine()}
+			}
 		} else if s.match('*') {
 			s.multiline_comment()
+		} else if s.match('=') {
+			s.addToken(SLASH_EQUAL)
 		} else {
 			s.addToken(SLASH)
 		}
 	case ' ':
 	case '\r':
 	case '\t':
-		// Ignore whitespace.
 	case '\n':
-		s.line++
+		// Ignore whitespace.
 	case '"':
 		s.string()
 	default:
@@ -124,14 +199,25 @@ func (s *Scanner) scanToken() {
 		} else if isAlpha(c) {
 			s.identifier()
 		} else {
-			s.reportError(s.line, "", "unexpected character")
+			s.reportError(s.line, s.startColumn, fmt.Sprintf("unexpected character '%c'", c))
 		}
 	}
 }
 
-func (s *Scanner) advance() byte {
-	c := s.source[s.current]
-	s.current++
+// tokenOnLine reports whether a token was scanned on the current line.
+func (s *Scanner) tokenOnLine() bool {
+	return len(s.tokens) > 0 && s.tokens[len(s.tokens)-1].Line == s.line
+}
+
+func (s *Scanner) advance() rune {
+	c, size := utf8.DecodeRuneInString(s.source[s.current:])
+	s.current += size
+	if c == '\n' {
+		s.line++
+		s.column = 0
+	} else {
+		s.column++
+	}
 	return c
 }
 
@@ -141,74 +227,203 @@ func (s *Scanner) addToken(tokenType TokenType) {
 
 func (s *Scanner) addTokenWithLiteral(tokenType TokenType, literal interface{}) {
 	text := s.source[s.start:s.current]
-	s.tokens = append(s.tokens, *NewToken(tokenType, text, literal, s.line))
+	s.tokens = append(s.tokens, *NewToken(tokenType, text, literal, s.line, s.startColumn))
 }
 
-func (s *Scanner) match(expected byte) bool {
-	if s.isAtEnd() {
+func (s *Scanner) match(expected rune) bool {
+	if s.peek() != expected || s.isAtEnd() {
 		return false
 	}
-	if s.source[s.current] != expected {
-		return false
-	}
-	s.current++
+	s.advance()
 	return true
 }
 
-func (s *Scanner) peek() byte {
+func (s *Scanner) peek() rune {
 	if s.isAtEnd() {
 		return '\000'
 	}
-	return s.source[s.current]
+	c, _ := utf8.DecodeRuneInString(s.source[s.current:])
+	return c
 }
 
-func (s *Scanner) peekNext() byte {
-	if s.current+1 >= len(s.source) {
+func (s *Scanner) peekNext() rune {
+	if s.isAtEnd() {
+		return '\000'
+	}
+	_, size := utf8.DecodeRuneInString(s.source[s.current:])
+	if s.current+size >= len(s.source) {
 		return '\000'
 	}
-	return s.source[s.current+1]
+	c, _ := utf8.DecodeRuneInString(s.source[s.current+size:])
+	return c
 }
 
 func (s *Scanner) string() {
+	var parts []interface{}
+	var value strings.Builder
+
 	for s.peek() != '"' && !s.isAtEnd() {
-		if s.peek() == '\n' {
-			s.line++
+		if s.peek() == '\\' {
+			s.escape(&value)
+			continue
 		}
-		s.advance()
+		if s.peek() == '$' && s.peekNext() == '{' {
+			parts = append(parts, value.String())
+			value.Reset()
+			// Consume the "${".
+			s.advance()
+			s.advance()
+			tokens := s.interpolation()
+			if tokens == nil {
+				return
+			}
+			parts = append(parts, tokens)
+			continue
+		}
+		value.WriteRune(s.advance())
 	}
 
 	if s.isAtEnd() {
-		s.reportError(s.line, "", "unterminated string")
+		s.reportError(s.line, s.column, "unterminated string")
 		return
 	}
 
 	// The closing ".
 	s.advance()
 
-	// Trim the surrounding quotes.
-	value := s.source[s.start+1 : s.current-1]
-	s.addTokenWithLiteral(STRING, value)
+	if parts == nil {
+		s.addTokenWithLiteral(STRING, value.String())
+		return
+	}
+	parts = append(parts, value.String())
+	s.addTokenWithLiteral(INTERPOLATION, parts)
+}
+
+// escape consumes an escape sequence starting at the backslash and writes the
+// character it denotes into value.
+func (s *Scanner) escape(value *strings.Builder) {
+	column := s.column + 1
+	// The \.
+	s.advance()
+	if s.isAtEnd() {
+		return
+	}
+
+	c := s.advance()
+	switch c {
+	case 'n':
+		value.WriteRune('\n')
+	case 't':
+		value.WriteRune('\t')
+	case 'r':
+		value.WriteRune('\r')
+	case '"', '\\', '$':
+		value.WriteRune(c)
+	case 'u':
+		if !s.match('{') {
+			s.reportError(s.line, column, "expect '{' after '\\u'")
+			return
+		}
+		start := s.current
+		for s.peek() != '}' && s.peek() != '"' && !s.isAtEnd() {
+			s.advance()
+		}
+		digits := s.source[start:s.current]
+		if !s.match('}') {
+			s.reportError(s.line, column, "unterminated unicode escape")
+			return
+		}
+		code, err := strconv.ParseUint(digits, 16, 32)
+		if err != nil || len(digits) > 6 || !utf8.ValidRune(rune(code)) {
+			s.reportError(s.line, column, fmt.Sprintf("invalid unicode escape '\\u{%v}'", digits))
+			return
+		}
+		value.WriteRune(rune(code))
+	default:
+		s.reportError(s.line, column, fmt.Sprintf("invalid escape sequence '\\%c'", c))
+	}
+}
+
+// interpolation scans the expression embedded in a string up to the matching
+// '}' and returns its tokens as a separate stream. The stream ends with
+// that '}' and EOF, so parse errors point at the closing brace.
+func (s *Scanner) interpolation() []Token {
+	nested := &Scanner{
+		source:   s.source,
+		tokens:   []Token{},
+		current:  s.current,
+		line:     s.line,
+		column:   s.column,
+		keywords: s.keywords,
+		ignored:  s.ignored,
+	}
+
+	depth := 0
+	for !nested.isAtEnd() {
+		c := nested.peek()
+		if c == '}' && depth == 0 {
+			break
+		}
+		if c == '{' {
+			depth++
+		} else if c == '}' {
+			depth--
+		}
+		nested.start = nested.current
+		nested.startColumn = nested.column + 1
+		nested.scanToken()
+	}
+
+	s.current = nested.current
+	s.line = nested.line
+	s.column = nested.column
+	if nested.hadError {
+		s.hadError = true
+	}
+
+	if s.isAtEnd() {
+		s.reportError(s.line, s.column, "unterminated string interpolation")
+		return nil
+	}
+
+	// The closing }.
+	s.advance()
+	closing := *NewToken(RIGHT_BRACE, "}", "", s.line, s.column)
+
+	return append(nested.tokens, closing, *NewToken(EOF, "", "", s.line, s.column))
 }
 
 func (s *Scanner) multiline_comment() {
 	for !s.isAtEnd() {
 		c := s.advance()
-		if c == '\n' {
-			s.line++
-		}
 		if c == '*' && s.match('/') {
 			break
 		}
 	}
 
 	if s.isAtEnd() {
-		s.reportError(s.line, "", "unterminated multiline comment")
+		s.reportError(s.line, s.column, "unterminated multiline comment")
 	}
 }
 
 func (s *Scanner) number() {
-	for isDigit(s.peek()) {
-		s.advance()
+	if s.source[s.start] == '0' {
+		switch s.peek() {
+		case 'x', 'X':
+			s.radixNumber(16, "hexadecimal", isHexDigit)
+			return
+		case 'b', 'B':
+			s.radixNumber(2, "binary", isBinaryDigit)
+			return
+		case 'o', 'O':
+			s.radixNumber(8, "octal", isOctalDigit)
+			return
+		}
+	}
+
+	// The first digit is already consumed.
+	if !s.digits(isDigit, true) {
+		return
 	}
 
 	// Look for a fractional part.
@@ -216,15 +431,104 @@ func (s *Scanner) number() {
 		// Consume the "."
 		s.advance()
 
-		for isDigit(s.peek()) {
-			s.advance()
+		if !s.digits(isDigit, false) {
+			return
+		}
+	}
+
+	// Look for an exponent.
+	if s.peek() == 'e' || s.peek() == 'E' {
+		s.advance()
+		if !s.match('+') {
+			s.match('-')
+		}
+		if !isDigit(s.peek()) {
+			s.numberError(s.startColumn, "expect digits in number exponent")
+			return
 		}
+		if !s.digits(isDigit, false) {
+			return
+		}
+	}
+
+	if !s.numberEnd("decimal") {
+		return
 	}
 
-	d, _ := strconv.ParseFloat(s.source[s.start:s.current], 64)
+	text := strings.ReplaceAll(s.source[s.start:s.current], "_", "")
+	d, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		s.numberError(s.startColumn, fmt.Sprintf("number literal %v out of range", s.source[s.start:s.current]))
+		return
+	}
 	s.addTokenWithLiteral(NUMBER, d)
 }
 
+// radixNumber scans an integer literal with a 0x, 0b or 0o prefix.
+func (s *Scanner) radixNumber(base int, name string, isRadixDigit func(rune) bool) {
+	// The base letter.
+	prefix := s.advance()
+
+	if !isRadixDigit(s.peek()) {
+		s.numberError(s.startColumn, fmt.Sprintf("expect %v digits after '0%c'", name, prefix))
+		return
+	}
+	if !s.digits(isRadixDigit, false) || !s.numberEnd(name) {
+		return
+	}
+
+	text := strings.ReplaceAll(s.source[s.start+2:s.current], "_", "")
+	n, err := strconv.ParseUint(text, base, 64)
+	if err != nil {
+		s.numberError(s.startColumn, fmt.Sprintf("number literal %v out of range", s.source[s.start:s.current]))
+		return
+	}
+	s.addTokenWithLiteral(NUMBER, float64(n))
+}
+
+// digits consumes a run of digits that may be separated by single underscores.
+// afterDigit tells whether a digit of the run was already consumed.
+func (s *Scanner) digits(isValid func(rune) bool, afterDigit bool) bool {
+	underscore := false
+	for isValid(s.peek()) || s.peek() == '_' {
+		c := s.advance()
+		if c != '_' {
+			underscore = false
+			afterDigit = true
+			continue
+		}
+		if underscore || !afterDigit {
+			s.numberError(s.column, "'_' must separate digits in number literal")
+			return false
+		}
+		underscore = true
+	}
+
+	if underscore {
+		s.numberError(s.column, "number literal can't end with '_'")
+		return false
+	}
+	return true
+}
+
+// numberEnd reports an error if the literal is directly followed by a letter
+// or digit that does not belong to it, like in 0b102 or 12px.
+func (s *Scanner) numberEnd(name string) bool {
+	if !isAlphaNumeric(s.peek()) {
+		return true
+	}
+	s.numberError(s.startColumn, fmt.Sprintf("invalid character '%c' in %v literal", s.peek(), name))
+	return false
+}
+
+// numberError reports a malformed number literal and skips the rest of it.
+func (s *Scanner) numberError(column int, message string) {
+	s.reportError(s.line, column, message)
+	for isAlphaNumeric(s.peek()) {
+		s.advance()
+	}
+}
+
 func (s *Scanner) identifier() {
 	for isAlphaNumeric(s.peek()) {
 		s.advance()
@@ -238,16 +542,26 @@ func (s *Scanner) identifier() {
 	s.addToken(tokenType)
 }
 
-func isDigit(c byte) bool {
+func isDigit(c rune) bool {
 	return c >= '0' && c <= '9'
 }
 
-func isAlpha(c byte) bool {
-	return (c >= 'a' && c <= 'z') ||
-		(c >= 'A' && c <= 'Z') ||
-		c == '_'
+func isHexDigit(c rune) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isOctalDigit(c rune) bool {
+	return c >= '0' && c <= '7'
+}
+
+func isBinaryDigit(c rune) bool {
+	return c == '0' || c == '1'
+}
+
+func isAlpha(c rune) bool {
+	return unicode.IsLetter(c) || c == '_'
 }
 
-func isAlphaNumeric(c byte) bool {
+func isAlphaNumeric(c rune) bool {
 	return isAlpha(c) || isDigit(c)
 }
@@ -0,0 +1,19 @@
+package lox
+
+import "testing"
+
+func TestInterpolationClosingBrace(t *testing.T) {
+	tokens, err := NewScanner(`"${ 1 + }"`).ScanTokens()
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+
+	nested := tokens[0].Literal.([]interface{})[1].([]Token)
+	closing := nested[len(nested)-2]
+	if closing.TokenType != RIGHT_BRACE || closing.Lexeme != "}" || closing.Line != 1 || closing.Column != 9 {
+		t.Errorf("got closing token %v %q at %v:%v, want '}' at 1:9", closing.TokenType, closing.Lexeme, closing.Line, closing.Column)
+	}
+	if end := nested[len(nested)-1]; end.TokenType != EOF {
+		t.Errorf("got %v after the closing brace, want EOF", end.TokenType)
+	}
+}
@@ -10,6 +10,10 @@ type StatementVisitor interface {
 	VisitFunctionStmt(stmt Function)
 	VisitReturnStmt(stmt Return)
 	VisitClassStmt(stmt Class)
+	VisitTraitStmt(stmt Trait)
+	VisitThrowStmt(stmt Throw)
+	VisitTryStmt(stmt Try)
+	VisitImportStmt(stmt Import)
 }
 
 type Stmt interface {
@@ -34,9 +38,12 @@ type Print struct {
 	Expression Expr
 }
 
+// Var declares a variable, or a constant that can't be reassigned when
+// Constant is set.
 type Var struct {
 	Name        Token
 	Initializer Expr
+	Constant    bool
 }
 
 type While struct {
@@ -44,10 +51,14 @@ type While struct {
 	Body      Stmt
 }
 
+// Function declares Params with their Defaults, which are nil for required
+// parameters, and an optional Rest parameter collecting extra arguments.
 type Function struct {
-	Name   Token
-	Params []Token
-	Body   []Stmt
+	Name     Token
+	Params   []Token
+	Defaults []Expr
+	Rest     *Token
+	Body     []Stmt
 }
 
 type Return struct {
@@ -55,10 +66,44 @@ type Return struct {
 	Value   Expr
 }
 
+// Class declares instance Methods, property Getters and Setters, and
+// ClassMethods, which are called on the class object itself. The methods of
+// Traits are included as instance methods. Finals name the fields that can
+// only be assigned while an instance is initialized.
 type Class struct {
-	Name       Token
-	Superclass *Variable
-	Methods    []Function
+	Name         Token
+	Superclass   *Variable
+	Traits       []*Variable
+	Finals       []Token
+	Methods      []Function
+	Getters      []Function
+	Setters      []Function
+	ClassMethods []Function
+}
+
+type Trait struct {
+	Name    Token
+	Methods []Function
+}
+
+type Throw struct {
+	Keyword Token
+	Value   Expr
+}
+
+type Try struct {
+	Body        []Stmt
+	CatchName   *Token
+	CatchBody   []Stmt
+	FinallyBody []Stmt
+}
+
+// Import binds either the whole module under Alias or the listed Names.
+type Import struct {
+	Keyword Token
+	Path    Token
+	Alias   *Token
+	Names   []Token
 }
 
 func NewIf(condition Expr, thenBranch Stmt, elseBranch Stmt) *If {
@@ -87,10 +132,11 @@ func NewPrint(expr Expr) *Print {
 	}
 }
 
-func NewVar(name Token, iniitializer Expr) *Var {
+func NewVar(name Token, iniitializer Expr, constant bool) *Var {
 	return &Var{
 		Name:        name,
 		Initializer: iniitializer,
+		Constant:    constant,
 	}
 }
 
@@ -101,11 +147,13 @@ func NewWhile(condition Expr, body Stmt) *While {
 	}
 }
 
-func NewFunction(name Token, params []Token, body []Stmt) *Function {
+func NewFunction(name Token, params []Token, defaults []Expr, rest *Token, body []Stmt) *Function {
 	return &Function{
-		Name:   name,
-		Params: params,
-		Body:   body,
+		Name:     name,
+		Params:   params,
+		Defaults: defaults,
+		Rest:     rest,
+		Body:     body,
 	}
 }
 
@@ -116,11 +164,48 @@ func NewReturn(keyword Token, value Expr) *Return {
 	}
 }
 
-func NewClass(name Token, superclass *Variable, methods []Function) *Class {
+func NewClass(name Token, superclass *Variable, traits []*Variable, finals []Token, methods, getters, setters, classMethods []Function) *Class {
 	return &Class{
-		Name:       name,
-		Superclass: superclass,
-		Methods:    methods,
+		Name:         name,
+		Superclass:   superclass,
+		Traits:       traits,
+		Finals:       finals,
+		Methods:      methods,
+		Getters:      getters,
+		Setters:      setters,
+		ClassMethods: classMethods,
+	}
+}
+
+func NewTrait(name Token, methods []Function) *Trait {
+	return &Trait{
+		Name:    name,
+		Methods: methods,
+	}
+}
+
+func NewThrow(keyword Token, value Expr) *Throw {
+	return &Throw{
+		Keyword: keyword,
+		Value:   value,
+	}
+}
+
+func NewTry(body []Stmt, catchName *Token, catchBody []Stmt, finallyBody []Stmt) *Try {
+	return &Try{
+		Body:        body,
+		CatchName:   catchName,
+		CatchBody:   catchBody,
+		FinallyBody: finallyBody,
+	}
+}
+
+func NewImport(keyword Token, path Token, alias *Token, names []Token) *Import {
+	return &Import{
+		Keyword: keyword,
+		Path:    path,
+		Alias:   alias,
+		Names:   names,
 	}
 }
 
@@ -159,3 +244,19 @@ func (r *Return) Accept(visitor StatementVisitor) {
 func (c *Class) Accept(visitor StatementVisitor) {
 	visitor.VisitClassStmt(*c)
 }
+
+func (t *Trait) Accept(visitor StatementVisitor) {
+	visitor.VisitTraitStmt(*t)
+}
+
+func (t *Throw) Accept(visitor StatementVisitor) {
+	visitor.VisitThrowStmt(*t)
+}
+
+func (t *Try) Accept(visitor StatementVisitor) {
+	visitor.VisitTryStmt(*t)
+}
+
+func (i *Import) Accept(visitor StatementVisitor) {
+	visitor.VisitImportStmt(*i)
+}
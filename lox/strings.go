@@ -0,0 +1,202 @@
+package lox
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+func defineStringNatives(env *Environment) {
+	env.Define("str", NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		return interpreter.stringify(arguments[0])
+	}))
+
+	env.Define("num", NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		switch v := arguments[0].(type) {
+		case float64:
+			return v
+		case string:
+			n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				interpreter.nativeError("num: can't convert '%v' to a number.", v)
+			}
+			return n
+		}
+		interpreter.nativeError("num: can't convert %v to a number.", stringify(arguments[0]))
+		return nil
+	}))
+
+	env.Define("chr", NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		code := interpreter.integerArgument("chr", arguments, 0)
+		if !utf8.ValidRune(rune(code)) {
+			interpreter.nativeError("chr: %v is not a valid character code.", code)
+		}
+		return string(rune(code))
+	}))
+
+	env.Define("ord", NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		s := interpreter.stringArgument("ord", arguments, 0)
+		if utf8.RuneCountInString(s) != 1 {
+			interpreter.nativeError("ord: expected a single character but got '%v'.", s)
+		}
+		r, _ := utf8.DecodeRuneInString(s)
+		return float64(r)
+	}))
+}
+
+// maxRepeatLength bounds the length of the strings built by repeat.
+const maxRepeatLength = 1 << 30
+
+// stringMethod returns the method name of the string s bound to it. Indices
+// and lengths count characters, not bytes.
+func stringMethod(s string, name Token) interface{} {
+	switch name.Lexeme {
+	case "len":
+		return NewProtoCallable(0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return float64(utf8.RuneCountInString(s))
+		})
+	case "upper":
+		return NewProtoCallable(0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return strings.ToUpper(s)
+		})
+	case "lower":
+		return NewProtoCallable(0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return strings.ToLower(s)
+		})
+	case "trim":
+		return NewProtoCallable(0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return strings.TrimSpace(s)
+		})
+	case "split":
+		return NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			var elements []interface{}
+			for _, part := range strings.Split(s, interpreter.stringArgument("split", arguments, 0)) {
+				elements = append(elements, part)
+			}
+			return NewLoxList(elements)
+		})
+	case "join":
+		return NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			list, ok := arguments[0].(*LoxList)
+			if !ok {
+				interpreter.nativeError("join: argument 1 must be a list.")
+			}
+			parts := make([]string, len(list.Elements))
+			for i, e := range list.Elements {
+				parts[i] = interpreter.stringify(e)
+			}
+			return strings.Join(parts, s)
+		})
+	case "replace":
+		return NewProtoCallable(2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			old := interpreter.stringArgument("replace", arguments, 0)
+			replacement := interpreter.stringArgument("replace", arguments, 1)
+			return strings.ReplaceAll(s, old, replacement)
+		})
+	case "indexOf":
+		return NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			i := strings.Index(s, interpreter.stringArgument("indexOf", arguments, 0))
+			if i < 0 {
+				return float64(-1)
+			}
+			return float64(utf8.RuneCountInString(s[:i]))
+		})
+	case "contains":
+		return NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return strings.Contains(s, interpreter.stringArgument("contains", arguments, 0))
+		})
+	case "startsWith":
+		return NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return strings.HasPrefix(s, interpreter.stringArgument("startsWith", arguments, 0))
+		})
+	case "endsWith":
+		return NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return strings.HasSuffix(s, interpreter.stringArgument("endsWith", arguments, 0))
+		})
+	case "substring":
+		// substring(start) or substring(start, end) with end exclusive.
+		return NewVariadicProtoCallable(1, 2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			runes := []rune(s)
+			start := interpreter.integerArgument("substring", arguments, 0)
+			end := len(runes)
+			if len(arguments) == 2 && arguments[1] != nil {
+				end = interpreter.integerArgument("substring", arguments, 1)
+			}
+			if start < 0 || end > len(runes) || start > end {
+				interpreter.nativeError("substring: range [%v, %v) out of bounds for string of length %v.", start, end, len(runes))
+			}
+			return string(runes[start:end])
+		}).WithParams("start", "end")
+	case "repeat":
+		return NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			count := interpreter.integerArgument("repeat", arguments, 0)
+			if count < 0 {
+				interpreter.nativeError("repeat: count must not be negative.")
+			}
+			if len(s) > 0 && count > maxRepeatLength/len(s) {
+				interpreter.nativeError("repeat: result would be longer than %v bytes.", maxRepeatLength)
+			}
+			return strings.Repeat(s, count)
+		})
+	case "chars":
+		return NewProtoCallable(0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			var elements []interface{}
+			for _, r := range s {
+				elements = append(elements, string(r))
+			}
+			return NewLoxList(elements)
+		})
+	case "format":
+		return NewVariadicProtoCallable(0, VariadicArity, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return format(interpreter, s, arguments)
+		})
+	}
+	panic(NewRuntimeError(name, fmt.Sprintf("undefined property %v .", name.Lexeme)))
+}
+
+// format replaces each {} in layout with the next argument and each {n} with
+// the n-th argument, counting from 0. {{ and }} stand for literal braces.
+func format(interpreter *Interpreter, layout string, arguments []interface{}) string {
+	var result strings.Builder
+	next := 0
+	runes := []rune(layout)
+
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		if (c == '{' || c == '}') && i+1 < len(runes) && runes[i+1] == c {
+			result.WriteRune(c)
+			i++
+			continue
+		}
+		if c != '{' {
+			result.WriteRune(c)
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) && runes[end] != '}' {
+			end++
+		}
+		if end == len(runes) {
+			interpreter.nativeError("format: unterminated placeholder.")
+		}
+
+		index := next
+		if placeholder := string(runes[i+1 : end]); placeholder != "" {
+			n, err := strconv.Atoi(placeholder)
+			if err != nil {
+				interpreter.nativeError("format: invalid placeholder '{%v}'.", placeholder)
+			}
+			index = n
+		} else {
+			next++
+		}
+		if index < 0 || index >= len(arguments) {
+			interpreter.nativeError("format: no argument for placeholder %v.", index)
+		}
+		result.WriteString(interpreter.stringify(arguments[index]))
+		i = end
+	}
+	return result.String()
+}
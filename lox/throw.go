@@ -0,0 +1,13 @@
+package lox
+
+type ThrowWrapper struct {
+	Keyword Token
+	Value   interface{}
+}
+
+func NewThrowWrapper(keyword Token, value interface{}) *ThrowWrapper {
+	return &ThrowWrapper{
+		Keyword: keyword,
+		Value:   value,
+	}
+}
@@ -0,0 +1,178 @@
+package lox
+
+import (
+	"fmt"
+	"time"
+)
+
+// Clock is the source of time for natives. Hosts can inject their own to make
+// scripts deterministic.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// SetClock sets the clock used by the clock native and the time module.
+func (i *Interpreter) SetClock(clock Clock) {
+	i.clock = clock
+}
+
+// LoxDate is a point in time. Durations are numbers of milliseconds.
+type LoxDate struct {
+	Time time.Time
+}
+
+func NewLoxDate(t time.Time) *LoxDate {
+	return &LoxDate{
+		Time: t,
+	}
+}
+
+func (d LoxDate) String() string {
+	return d.Time.Format("2006-01-02T15:04:05.000Z07:00")
+}
+
+func (d *LoxDate) Get(name Token) interface{} {
+	switch name.Lexeme {
+	case "year":
+		return float64(d.Time.Year())
+	case "month":
+		return float64(d.Time.Month())
+	case "day":
+		return float64(d.Time.Day())
+	case "hour":
+		return float64(d.Time.Hour())
+	case "minute":
+		return float64(d.Time.Minute())
+	case "second":
+		return float64(d.Time.Second())
+	case "millisecond":
+		return float64(d.Time.Nanosecond() / int(time.Millisecond))
+	case "weekday":
+		return float64(d.Time.Weekday())
+	case "unix":
+		return seconds(d.Time)
+	case "add":
+		return NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return NewLoxDate(d.Time.Add(interpreter.durationArgument("add", arguments, 0)))
+		})
+	case "diff":
+		return NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return milliseconds(d.Time.Sub(interpreter.dateArgument("diff", arguments, 0)))
+		})
+	case "before":
+		return NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return d.Time.Before(interpreter.dateArgument("before", arguments, 0))
+		})
+	case "after":
+		return NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return d.Time.After(interpreter.dateArgument("after", arguments, 0))
+		})
+	case "utc":
+		return NewProtoCallable(0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return NewLoxDate(d.Time.UTC())
+		})
+	case "format":
+		return NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return d.Time.Format(interpreter.stringArgument("format", arguments, 0))
+		})
+	}
+	panic(NewRuntimeError(name, fmt.Sprintf("undefined property %v .", name.Lexeme)))
+}
+
+// newTimeModule builds the time module. Layouts for format and parse use the
+// reference time of Go's time package, Mon Jan 2 15:04:05 MST 2006.
+func newTimeModule() *LoxModule {
+	values := map[string]interface{}{
+		"RFC3339":  time.RFC3339,
+		"DATE":     time.DateOnly,
+		"TIME":     time.TimeOnly,
+		"DATETIME": time.DateTime,
+	}
+
+	values["clock"] = NewProtoCallable(0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		return seconds(interpreter.clock.Now())
+	})
+
+	values["now"] = NewProtoCallable(0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		return NewLoxDate(interpreter.clock.Now())
+	})
+
+	values["fromUnix"] = NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		s := interpreter.numberArgument("fromUnix", arguments, 0)
+		return NewLoxDate(time.UnixMilli(int64(s * 1000)).UTC())
+	})
+
+	values["format"] = NewProtoCallable(2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		t := interpreter.dateArgument("format", arguments, 0)
+		return t.Format(interpreter.stringArgument("format", arguments, 1))
+	})
+
+	values["parse"] = NewProtoCallable(2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		value := interpreter.stringArgument("parse", arguments, 0)
+		layout := interpreter.stringArgument("parse", arguments, 1)
+		t, err := time.Parse(layout, value)
+		if err != nil {
+			interpreter.nativeError("parse: %v.", err)
+		}
+		return NewLoxDate(t)
+	}).WithParams("value", "layout")
+
+	values["sleep"] = NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		d := interpreter.durationArgument("sleep", arguments, 0)
+		if d < 0 {
+			interpreter.nativeError("sleep: duration must not be negative.")
+		}
+		interpreter.clock.Sleep(d)
+		return nil
+	})
+
+	values["since"] = NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		return milliseconds(interpreter.clock.Now().Sub(interpreter.dateArgument("since", arguments, 0)))
+	})
+
+	units := map[string]time.Duration{
+		"seconds": time.Second,
+		"minutes": time.Minute,
+		"hours":   time.Hour,
+		"days":    24 * time.Hour,
+	}
+	for name, unit := range units {
+		name, unit := name, unit
+		values[name] = NewProtoCallable(1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return interpreter.numberArgument(name, arguments, 0) * milliseconds(unit)
+		})
+	}
+
+	return NewLoxModule("time", "", values)
+}
+
+func seconds(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(time.Second)
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func (i *Interpreter) durationArgument(name string, arguments []interface{}, index int) time.Duration {
+	return time.Duration(i.numberArgument(name, arguments, index) * float64(time.Millisecond))
+}
+
+func (i *Interpreter) dateArgument(name string, arguments []interface{}, index int) time.Time {
+	d, ok := arguments[index].(*LoxDate)
+	if !ok {
+		i.nativeError("%v: argument %v must be a date.", name, index+1)
+	}
+	return d.Time
+}
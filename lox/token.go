@@ -7,14 +7,16 @@ type Token struct {
 	Lexeme    string
 	Literal   interface{}
 	Line      int
+	Column    int
 }
 
-func NewToken(tokenType TokenType, lexeme string, literal interface{}, line int) *Token {
+func NewToken(tokenType TokenType, lexeme string, literal interface{}, line int, column int) *Token {
 	return &Token{
 		TokenType: tokenType,
 		Lexeme:    lexeme,
 		Literal:   literal,
 		Line:      line,
+		Column:    column,
 	}
 }
 
@@ -12,11 +12,17 @@ const (
 	RIGHT_BRACE
 	COMMA
 	DOT
+	DOT_DOT_DOT
 	MINUS
 	PLUS
 	SEMICOLON
 	SLASH
 	STAR
+	PERCENT
+	AMPERSAND
+	PIPE
+	CARET
+	COLON
 	// One or two character tokens.
 	BANG
 	BANG_EQUAL
@@ -24,29 +30,52 @@ const (
 	EQUAL_EQUAL
 	GREATER
 	GREATER_EQUAL
+	GREATER_GREATER
 	LESS
 	LESS_EQUAL
+	LESS_LESS
+	MINUS_EQUAL
+	MINUS_MINUS
+	PERCENT_EQUAL
+	PLUS_EQUAL
+	PLUS_PLUS
+	QUESTION
+	QUESTION_DOT
+	QUESTION_QUESTION
+	SLASH_EQUAL
+	STAR_EQUAL
+	STAR_STAR
+	TILDE
+	TILDE_SLASH
 
 	// Literals.
 	IDENTIFIER
 	STRING
+	INTERPOLATION
 	NUMBER
 
 	// Keywords.
 	AND
+	CATCH
 	CLASS
+	CONST
 	ELSE
 	FALSE
+	FINALLY
 	FUN
 	FOR
 	IF
+	IMPORT
 	NIL
 	OR
 	PRINT
 	RETURN
 	SUPER
 	THIS
+	THROW
+	TRAIT
 	TRUE
+	TRY
 	VAR
 	WHILE
 
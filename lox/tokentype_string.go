@@ -14,44 +14,73 @@ func _() {
 	_ = x[RIGHT_BRACE-3]
 	_ = x[COMMA-4]
 	_ = x[DOT-5]
-	_ = x[MINUS-6]
-	_ = x[PLUS-7]
-	_ = x[SEMICOLON-8]
-	_ = x[SLASH-9]
-	_ = x[STAR-10]
-	_ = x[BANG-11]
-	_ = x[BANG_EQUAL-12]
-	_ = x[EQUAL-13]
-	_ = x[EQUAL_EQUAL-14]
-	_ = x[GREATER-15]
-	_ = x[GREATER_EQUAL-16]
-	_ = x[LESS-17]
-	_ = x[LESS_EQUAL-18]
-	_ = x[IDENTIFIER-19]
-	_ = x[STRING-20]
-	_ = x[NUMBER-21]
-	_ = x[AND-22]
-	_ = x[CLASS-23]
-	_ = x[ELSE-24]
-	_ = x[FALSE-25]
-	_ = x[FUN-26]
-	_ = x[FOR-27]
-	_ = x[IF-28]
-	_ = x[NIL-29]
-	_ = x[OR-30]
-	_ = x[PRINT-31]
-	_ = x[RETURN-32]
-	_ = x[SUPER-33]
-	_ = x[THIS-34]
-	_ = x[TRUE-35]
-	_ = x[VAR-36]
-	_ = x[WHILE-37]
-	_ = x[EOF-38]
+	_ = x[DOT_DOT_DOT-6]
+	_ = x[MINUS-7]
+	_ = x[PLUS-8]
+	_ = x[SEMICOLON-9]
+	_ = x[SLASH-10]
+	_ = x[STAR-11]
+	_ = x[PERCENT-12]
+	_ = x[AMPERSAND-13]
+	_ = x[PIPE-14]
+	_ = x[CARET-15]
+	_ = x[COLON-16]
+	_ = x[BANG-17]
+	_ = x[BANG_EQUAL-18]
+	_ = x[EQUAL-19]
+	_ = x[EQUAL_EQUAL-20]
+	_ = x[GREATER-21]
+	_ = x[GREATER_EQUAL-22]
+	_ = x[GREATER_GREATER-23]
+	_ = x[LESS-24]
+	_ = x[LESS_EQUAL-25]
+	_ = x[LESS_LESS-26]
+	_ = x[MINUS_EQUAL-27]
+	_ = x[MINUS_MINUS-28]
+	_ = x[PERCENT_EQUAL-29]
+	_ = x[PLUS_EQUAL-30]
+	_ = x[PLUS_PLUS-31]
+	_ = x[QUESTION-32]
+	_ = x[QUESTION_DOT-33]
+	_ = x[QUESTION_QUESTION-34]
+	_ = x[SLASH_EQUAL-35]
+	_ = x[STAR_EQUAL-36]
+	_ = x[STAR_STAR-37]
+	_ = x[TILDE-38]
+	_ = x[TILDE_SLASH-39]
+	_ = x[IDENTIFIER-40]
+	_ = x[STRING-41]
+	_ = x[INTERPOLATION-42]
+	_ = x[NUMBER-43]
+	_ = x[AND-44]
+	_ = x[CATCH-45]
+	_ = x[CLASS-46]
+	_ = x[CONST-47]
+	_ = x[ELSE-48]
+	_ = x[FALSE-49]
+	_ = x[FINALLY-50]
+	_ = x[FUN-51]
+	_ = x[FOR-52]
+	_ = x[IF-53]
+	_ = x[IMPORT-54]
+	_ = x[NIL-55]
+	_ = x[OR-56]
+	_ = x[PRINT-57]
+	_ = x[RETURN-58]
+	_ = x[SUPER-59]
+	_ = x[THIS-60]
+	_ = x[THROW-61]
+	_ = x[TRAIT-62]
+	_ = x[TRUE-63]
+	_ = x[TRY-64]
+	_ = x[VAR-65]
+	_ = x[WHILE-66]
+	_ = x[EOF-67]
 }
 
-const _TokenType_name = "LEFT_PARENRIGHT_PARENLEFT_BRACERIGHT_BRACECOMMADOTMINUSPLUSSEMICOLONSLASHSTARBANGBANG_EQUALEQUALEQUAL_EQUALGREATERGREATER_EQUALLESSLESS_EQUALIDENTIFIERSTRINGNUMBERANDCLASSELSEFALSEFUNFORIFNILORPRINTRETURNSUPERTHISTRUEVARWHILEEOF"
+const _TokenType_name = "LEFT_PARENRIGHT_PARENLEFT_BRACERIGHT_BRACECOMMADOTDOT_DOT_DOTMINUSPLUSSEMICOLONSLASHSTARPERCENTAMPERSANDPIPECARETCOLONBANGBANG_EQUALEQUALEQUAL_EQUALGREATERGREATER_EQUALGREATER_GREATERLESSLESS_EQUALLESS_LESSMINUS_EQUALMINUS_MINUSPERCENT_EQUALPLUS_EQUALPLUS_PLUSQUESTIONQUESTION_DOTQUESTION_QUESTIONSLASH_EQUALSTAR_EQUALSTAR_STARTILDETILDE_SLASHIDENTIFIERSTRINGINTERPOLATIONNUMBERANDCATCHCLASSCONSTELSEFALSEFINALLYFUNFORIFIMPORTNILORPRINTRETURNSUPERTHISTHROWTRAITTRUETRYVARWHILEEOF"
 
-var _TokenType_index = [...]uint8{0, 10, 21, 31, 42, 47, 50, 55, 59, 68, 73, 77, 81, 91, 96, 107, 114, 127, 131, 141, 151, 157, 163, 166, 171, 175, 180, 183, 186, 188, 191, 193, 198, 204, 209, 213, 217, 220, 225, 228}
+var _TokenType_index = [...]uint16{0, 10, 21, 31, 42, 47, 50, 61, 66, 70, 79, 84, 88, 95, 104, 108, 113, 118, 122, 132, 137, 148, 155, 168, 183, 187, 197, 206, 217, 228, 241, 251, 260, 268, 280, 297, 308, 318, 327, 332, 343, 353, 359, 372, 378, 381, 386, 391, 396, 400, 405, 412, 415, 418, 420, 426, 429, 431, 436, 442, 447, 451, 456, 461, 465, 468, 471, 476, 479}
 
 func (i TokenType) String() string {
 	if i < 0 || i >= TokenType(len(_TokenType_index)-1) {
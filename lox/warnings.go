@@ -0,0 +1,182 @@
+package lox
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Warning codes reported by the resolver. A warning is suppressed by a
+// '// lox:ignore W001' comment at the end of its line, or alone on the line
+// before it.
+const (
+	WARN_UNUSED_VARIABLE      = "W001"
+	WARN_UNUSED_PARAMETER     = "W002"
+	WARN_UNREACHABLE_CODE     = "W003"
+	WARN_SHADOWED_VARIABLE    = "W004"
+	WARN_UNDECLARED_GLOBAL    = "W005"
+	WARN_WRONG_ARGUMENTS      = "W006"
+	WARN_THIS_IN_CLASS_METHOD = "W007"
+)
+
+const IGNORE_DIRECTIVE = "lox:ignore"
+
+type Warning struct {
+	Code    string
+	Token   Token
+	Message string
+}
+
+func NewWarning(code string, token Token, message string) *Warning {
+	return &Warning{
+		Code:    code,
+		Token:   token,
+		Message: message,
+	}
+}
+
+func (w *Warning) String() string {
+	return fmt.Sprintf("[line %v] Warning %v at %v: %v", w.Token.Line, w.Code, w.Token.Lexeme, w.Message)
+}
+
+// IgnoreComment is a '// lox:ignore' comment. A comment alone on its line
+// also applies to the next line.
+type IgnoreComment struct {
+	Codes      []string
+	Standalone bool
+}
+
+// ignores reports whether the comment suppresses the warning code. A comment
+// without codes suppresses every warning.
+func (c IgnoreComment) ignores(code string) bool {
+	if len(c.Codes) == 0 {
+		return true
+	}
+	for _, ignored := range c.Codes {
+		if ignored == code {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoreDirective returns the warning codes listed by a '// lox:ignore'
+// comment. An empty list ignores every warning.
+func ignoreDirective(comment string) ([]string, bool) {
+	text := strings.TrimSpace(strings.TrimPrefix(comment, "//"))
+	if !strings.HasPrefix(text, IGNORE_DIRECTIVE) {
+		return nil, false
+	}
+	codes := strings.FieldsFunc(text[len(IGNORE_DIRECTIVE):], func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	return codes, true
+}
+
+// IgnoreWarnings suppresses the warnings listed per line by the scanner's
+// IgnoredWarnings.
+func (r *Resolver) IgnoreWarnings(ignored map[int]IgnoreComment) {
+	r.ignored = ignored
+}
+
+func (r *Resolver) isIgnored(code string, line int) bool {
+	if comment, ok := r.ignored[line]; ok && comment.ignores(code) {
+		return true
+	}
+	comment, ok := r.ignored[line-1]
+	return ok && comment.Standalone && comment.ignores(code)
+}
+
+func (r *Resolver) warn(code string, token Token, message string) {
+	if r.isIgnored(code, token.Line) {
+		return
+	}
+	r.warnings = append(r.warnings, NewWarning(code, token, message))
+}
+
+// reportWarnings runs the checks that need the whole program, then prints
+// the warnings in source order.
+func (r *Resolver) reportWarnings() {
+	for _, name := range r.globalAssignments {
+		delete(r.globalFunctions, name.Lexeme)
+		if !r.isGlobal(name.Lexeme) {
+			r.warn(WARN_UNDECLARED_GLOBAL, name, fmt.Sprintf("assignment to undeclared global '%v'.", name.Lexeme))
+		}
+	}
+	for _, call := range r.globalCalls {
+		if function, ok := r.globalFunctions[call.name.Lexeme]; ok {
+			r.checkCall(function, call.name, call.arguments, call.named)
+		}
+	}
+	r.globalAssignments, r.globalCalls = nil, nil
+
+	sort.SliceStable(r.warnings, func(a, b int) bool {
+		if r.warnings[a].Token.Line != r.warnings[b].Token.Line {
+			return r.warnings[a].Token.Line < r.warnings[b].Token.Line
+		}
+		return r.warnings[a].Token.Column < r.warnings[b].Token.Column
+	})
+	for _, warning := range r.warnings {
+		fmt.Fprintln(r.warningOutput, warning)
+	}
+	r.warnings = nil
+}
+
+// isGlobal reports whether name is declared by the program, or already
+// defined by an earlier program or as a builtin.
+func (r *Resolver) isGlobal(name string) bool {
+	if r.globals[name] {
+		return true
+	}
+	if _, ok := r.interpreter.globals.Values[name]; ok {
+		return true
+	}
+	_, ok := r.interpreter.builtins.Values[name]
+	return ok
+}
+
+// checkCall warns when a call to function passes a number of arguments the
+// function can't accept. Only too many arguments are detected when some of
+// them are named.
+func (r *Resolver) checkCall(function *Function, name Token, arguments int, named bool) {
+	min, max := len(function.Params), len(function.Params)
+	for min > 0 && function.Defaults[min-1] != nil {
+		min--
+	}
+	if function.Rest != nil {
+		max = VariadicArity
+	}
+
+	if (arguments < min && !named) || (max != VariadicArity && arguments > max) {
+		r.warn(WARN_WRONG_ARGUMENTS, name, fmt.Sprintf("'%v' expects %v arguments but is called with %v.", name.Lexeme, arityDescription(min, max), arguments))
+	}
+}
+
+// checkUnreachable warns about statements following a return or throw.
+func (r *Resolver) checkUnreachable(statements []Stmt) {
+	for j := 0; j < len(statements)-1; j++ {
+		switch s := statements[j].(type) {
+		case *Return:
+			r.warn(WARN_UNREACHABLE_CODE, s.Keyword, "unreachable code after 'return'.")
+			return
+		case *Throw:
+			r.warn(WARN_UNREACHABLE_CODE, s.Keyword, "unreachable code after 'throw'.")
+			return
+		}
+	}
+}
+
+// checkUnused warns about the variables of scope that are never read.
+// Names starting with '_' are exempt.
+func (r *Resolver) checkUnused(scope map[string]*variable) {
+	for name, v := range scope {
+		if v.used || v.kind == VARIABLE_IMPLICIT || strings.HasPrefix(name, "_") {
+			continue
+		}
+		if v.kind == VARIABLE_PARAMETER {
+			r.warn(WARN_UNUSED_PARAMETER, v.name, fmt.Sprintf("parameter '%v' is never used.", name))
+		} else {
+			r.warn(WARN_UNUSED_VARIABLE, v.name, fmt.Sprintf("local variable '%v' is never used.", name))
+		}
+	}
+}
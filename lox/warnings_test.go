@@ -0,0 +1,60 @@
+package lox
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// resolveWarnings resolves source and returns the warnings it reports.
+func resolveWarnings(t *testing.T, source string) []string {
+	t.Helper()
+
+	scanner := NewScanner(source)
+	tokens, err := scanner.ScanTokens()
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	statements, err := NewParser(tokens).Parse()
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	var out bytes.Buffer
+	resolver := NewResolver(NewInterpreter())
+	resolver.SetWarningOutput(&out)
+	resolver.IgnoreWarnings(scanner.IgnoredWarnings())
+	if err := resolver.ResolveStatements(statements); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	return strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+}
+
+func expectWarnings(t *testing.T, source string, expected ...string) {
+	t.Helper()
+
+	warnings := resolveWarnings(t, source)
+	if strings.Join(warnings, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("got warnings\n%v\nwant\n%v", strings.Join(warnings, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestIgnoreComments(t *testing.T) {
+	expectWarnings(t, `{
+  var a = 1; // lox:ignore W001
+  var b = 2;
+  // lox:ignore W001
+  var c = 3;
+  var d = 4; // lox:ignore W002
+}`, "[line 3] Warning W001 at b: local variable 'b' is never used.",
+		"[line 6] Warning W001 at d: local variable 'd' is never used.")
+}
+
+func TestWrongArgumentsAfterAssignment(t *testing.T) {
+	expectWarnings(t, `{
+  fun local(x) { return x; }
+  local(1, 2);
+  local = clock;
+  local();
+}`, "[line 3] Warning W006 at local: 'local' expects 1 arguments but is called with 2.")
+}